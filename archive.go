@@ -0,0 +1,649 @@
+package sidetable
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ArchiveFormatVersion is the archive format written by Export.
+	ArchiveFormatVersion = 1
+
+	archiveManifestName = "manifest.json"
+	archiveManifestPerm = 0o644
+	archiveToolsDir     = "tools"
+	archiveBackupInfix  = ".bak."
+	archiveBackupFormat = "20060102-150405"
+	// archiveStagingPrefix starts the names of the directories Import extracts archives into.
+	archiveStagingPrefix = ".import-"
+
+	// MaxImportSize is the largest total size of files Import extracts from an archive.
+	MaxImportSize = 16 << 30
+	// maxLinkHops bounds how many symbolic links are followed when checking where an extracted link points.
+	maxLinkHops = 40
+)
+
+var (
+	// ErrArchiveInvalid is returned when an archive is not a sidetable tool area archive or is unsafe to extract.
+	ErrArchiveInvalid = errors.New("invalid archive")
+	// ErrConflictStrategyUnknown is returned for conflict strategies other than skip, overwrite and rename.
+	ErrConflictStrategyUnknown = errors.New("unknown conflict strategy")
+)
+
+// ArchiveManifest describes the contents of a tool area archive. It is the first entry of every archive.
+type ArchiveManifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConfigHash is the SHA-256 of the config file the archive was exported with.
+	ConfigHash string        `json:"config_hash"`
+	Tools      []ArchiveTool `json:"tools"`
+}
+
+// ArchiveTool describes a tool directory in an archive.
+type ArchiveTool struct {
+	Name    string    `json:"name"`
+	ModTime time.Time `json:"mod_time"`
+	Files   int       `json:"files"`
+	Size    int64     `json:"size"`
+}
+
+// ConflictStrategy decides what Import does when a tool directory already exists.
+type ConflictStrategy string
+
+const (
+	// ConflictSkip keeps the existing directory and does not import the tool.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictOverwrite removes the existing directory before importing.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// ConflictRename moves the existing directory to "<name>.bak.<timestamp>" before importing.
+	// AreaStatus lists these backups separately from orphaned directories.
+	ConflictRename ConflictStrategy = "rename"
+)
+
+// ImportAction describes what Import did with a tool.
+type ImportAction string
+
+const (
+	// ImportActionImported marks tools extracted into a new directory.
+	ImportActionImported ImportAction = "imported"
+	// ImportActionSkipped marks tools that were not extracted; see ImportedTool.Reason.
+	ImportActionSkipped ImportAction = "skipped"
+	// ImportActionOverwritten marks tools that replaced an existing directory.
+	ImportActionOverwritten ImportAction = "overwritten"
+	// ImportActionRenamed marks tools whose existing directory was moved to a backup first.
+	ImportActionRenamed ImportAction = "renamed"
+)
+
+// ImportedTool reports the outcome for a tool in an archive.
+type ImportedTool struct {
+	Name   string
+	Path   string
+	Action ImportAction
+	// Reason explains why the tool was skipped.
+	Reason string
+	// Backup is where the existing directory was moved with ConflictRename.
+	Backup string
+}
+
+// ImportResult reports the outcome of Import.
+type ImportResult struct {
+	Manifest *ArchiveManifest
+	// ConfigMatches is true when the archive was exported with a config identical to the current one.
+	ConfigMatches bool
+	Tools         []ImportedTool
+}
+
+// Export writes a gzip-compressed tar archive of tool directories to out.
+//
+// names may contain tools and aliases; aliases export the directory of their target tool.
+// Without names, every configured tool with an existing directory is exported.
+// Symbolic links are archived as links and never followed.
+func (w *Workspace) Export(out io.Writer, names []string) (*ArchiveManifest, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+
+	tools, err := w.exportToolNames(names)
+	if err != nil {
+		return nil, err
+	}
+	configHash, err := w.configHash()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ArchiveManifest{
+		Version:    ArchiveFormatVersion,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: configHash,
+		Tools:      make([]ArchiveTool, 0, len(tools)),
+	}
+	for _, name := range tools {
+		dir, statErr := statDir(toolDir(w.rootDir, w.config, name))
+		if statErr != nil {
+			return nil, statErr
+		}
+		switch {
+		case !dir.Exists && len(names) == 0:
+			continue
+		case !dir.Exists:
+			return nil, fmt.Errorf("tool directory of %q does not exist: %s", name, dir.Path)
+		case dir.Symlink:
+			return nil, fmt.Errorf("tool directory of %q is a symbolic link: %s", name, dir.Path)
+		}
+		// Internal files are not archived, so they are not counted either.
+		for _, internal := range internalToolFiles {
+			if info, lstatErr := os.Lstat(filepath.Join(dir.Path, internal)); lstatErr == nil && info.Mode().IsRegular() {
+				dir.Files--
+				dir.Size -= info.Size()
+			}
+		}
+		manifest.Tools = append(manifest.Tools, ArchiveTool{
+			Name:    name,
+			ModTime: dir.ModTime.UTC(),
+			Files:   dir.Files,
+			Size:    dir.Size,
+		})
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	if err = writeArchiveManifest(tw, manifest); err != nil {
+		return nil, err
+	}
+	for _, tool := range manifest.Tools {
+		dir := toolDir(w.rootDir, w.config, tool.Name)
+		if err = writeArchiveDir(tw, dir, path.Join(archiveToolsDir, tool.Name)); err != nil {
+			return nil, err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	if err = gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// exportToolNames resolves names to sorted, unique tool names.
+func (w *Workspace) exportToolNames(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return w.config.ToolNames(), nil
+	}
+
+	seen := make(map[string]bool, len(names))
+	tools := make([]string, 0, len(names))
+	for _, name := range names {
+		resolved, err := w.config.ResolveEntry(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[resolved.ToolName] {
+			seen[resolved.ToolName] = true
+			tools = append(tools, resolved.ToolName)
+		}
+	}
+	sort.Strings(tools)
+	return tools, nil
+}
+
+func (w *Workspace) configHash() (string, error) {
+	data, err := os.ReadFile(w.config.FilePath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+func writeArchiveManifest(tw *tar.Writer, manifest *ArchiveManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     archiveManifestName,
+		Mode:     archiveManifestPerm,
+		Size:     int64(len(data)),
+		ModTime:  manifest.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// writeArchiveDir adds dir and everything in it to tw under prefix.
+// Only directories, regular files and symbolic links are archived. Internal files such as the tool lock are left out.
+func writeArchiveDir(tw *tar.Writer, dir, prefix string) error {
+	// WalkDir does not follow symlinks, so the walk stays inside dir.
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if filepath.Dir(p) == dir && slices.Contains(internalToolFiles, d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.IsDir() && !info.Mode().IsRegular():
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		header.Uname, header.Gname = "", ""
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// Import extracts a tool area archive written by Export into the tool area.
+//
+// The archive may be gzip-compressed or plain. Entries are extracted to a staging directory first;
+// absolute paths, ".." elements, writes through symbolic links and symbolic links pointing outside their tool directory
+// are rejected. Every tool may hold at most the size the manifest declares for it, and the archive at most MaxImportSize.
+// Tools that are not configured in the current config are skipped.
+func (w *Workspace) Import(in io.Reader, strategy ConflictStrategy) (*ImportResult, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+	switch strategy {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("%w %q: must be one of skip, overwrite, rename", ErrConflictStrategyUnknown, strategy)
+	}
+
+	area := w.AreaDir()
+	if err := os.MkdirAll(area, toolDirPerm); err != nil {
+		return nil, err
+	}
+	if err := w.checkAreaInWorkspace(area); err != nil {
+		return nil, err
+	}
+
+	tr, err := newArchiveReader(in)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := readArchiveManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(area, archiveStagingPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	if err = extractArchive(tr, staging, manifest); err != nil {
+		return nil, err
+	}
+
+	configHash, err := w.configHash()
+	if err != nil {
+		return nil, err
+	}
+	result := &ImportResult{Manifest: manifest, ConfigMatches: configHash == manifest.ConfigHash}
+	now := time.Now()
+	for _, tool := range manifest.Tools {
+		imported, placeErr := w.placeImportedTool(filepath.Join(staging, archiveToolsDir, tool.Name), tool.Name, strategy, now)
+		if placeErr != nil {
+			return result, placeErr
+		}
+		result.Tools = append(result.Tools, imported)
+	}
+	return result, nil
+}
+
+// placeImportedTool moves an extracted tool directory into the tool area, resolving conflicts with strategy.
+func (w *Workspace) placeImportedTool(src, name string, strategy ConflictStrategy, now time.Time) (ImportedTool, error) {
+	dest := toolDir(w.rootDir, w.config, name)
+	imported := ImportedTool{Name: name, Path: dest, Action: ImportActionImported}
+	if _, configured := w.config.Tools[name]; !configured {
+		imported.Action = ImportActionSkipped
+		imported.Reason = "not configured"
+		return imported, nil
+	}
+
+	_, err := os.Lstat(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return imported, os.Rename(src, dest)
+	}
+	if err != nil {
+		return imported, err
+	}
+	if strategy == ConflictSkip {
+		imported.Action = ImportActionSkipped
+		imported.Reason = "already exists"
+		return imported, nil
+	}
+	// The directory of a running service or a locked tool is never replaced.
+	reason, err := w.areaDirInUse(name)
+	if err != nil {
+		return imported, err
+	}
+	if reason != "" {
+		imported.Action = ImportActionSkipped
+		imported.Reason = reason
+		return imported, nil
+	}
+
+	if strategy == ConflictOverwrite {
+		if err = w.RemoveAreaDir(name); err != nil {
+			return imported, err
+		}
+		imported.Action = ImportActionOverwritten
+	} else {
+		imported.Backup = dest + archiveBackupInfix + now.Format(archiveBackupFormat)
+		if err = os.Rename(dest, imported.Backup); err != nil {
+			return imported, err
+		}
+		imported.Action = ImportActionRenamed
+	}
+
+	if err = os.Rename(src, dest); err != nil {
+		return imported, err
+	}
+	return imported, nil
+}
+
+// newArchiveReader returns a tar reader for in, decompressing it when it starts with the gzip magic number.
+func newArchiveReader(in io.Reader) (*tar.Reader, error) {
+	br := bufio.NewReader(in)
+	magic, err := br.Peek(2) //nolint:mnd // length of the gzip magic number
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, gzErr := gzip.NewReader(br)
+		if gzErr != nil {
+			return nil, gzErr
+		}
+		return tar.NewReader(gz), nil
+	}
+	return tar.NewReader(br), nil
+}
+
+func readArchiveManifest(tr *tar.Reader) (*ArchiveManifest, error) {
+	header, err := tr.Next()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: archive is empty", ErrArchiveInvalid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+	}
+	if header.Name != archiveManifestName {
+		return nil, fmt.Errorf("%w: first entry must be %s, got %s", ErrArchiveInvalid, archiveManifestName, header.Name)
+	}
+
+	var manifest ArchiveManifest
+	if err = json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %w", ErrArchiveInvalid, err)
+	}
+	if manifest.Version > ArchiveFormatVersion {
+		return nil, fmt.Errorf(
+			"%w: archive format %d is newer than supported format %d; please upgrade sidetable",
+			ErrArchiveInvalid, manifest.Version, ArchiveFormatVersion,
+		)
+	}
+	var total int64
+	for _, tool := range manifest.Tools {
+		if err = checkAreaEntryName(tool.Name); err != nil {
+			return nil, fmt.Errorf("%w: manifest: %w", ErrArchiveInvalid, err)
+		}
+		if tool.Size < 0 {
+			return nil, fmt.Errorf("%w: manifest: negative size for %q", ErrArchiveInvalid, tool.Name)
+		}
+		total += tool.Size
+		if total > MaxImportSize {
+			return nil, fmt.Errorf("%w: manifest: archive is larger than %d bytes", ErrArchiveInvalid, int64(MaxImportSize))
+		}
+	}
+	return &manifest, nil
+}
+
+// extractArchive extracts the tool entries of tr below dest.
+// Every entry must belong to a tool listed in manifest, and the files of a tool must fit in its declared size.
+func extractArchive(tr *tar.Reader, dest string, manifest *ArchiveManifest) error {
+	// remaining holds how many more bytes of files each tool may extract.
+	remaining := make(map[string]int64, len(manifest.Tools))
+	for _, tool := range manifest.Tools {
+		remaining[tool.Name] = tool.Size
+		if err := os.MkdirAll(filepath.Join(dest, archiveToolsDir, tool.Name), toolDirPerm); err != nil {
+			return err
+		}
+	}
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrArchiveInvalid, err)
+		}
+
+		name := strings.TrimSuffix(header.Name, "/")
+		rel := filepath.FromSlash(name)
+		parts := strings.SplitN(name, "/", 3) //nolint:mnd // "tools", tool name and the rest
+		// Names must already be clean: IsLocal alone accepts "tools/x/../../y" because it cleans first.
+		if path.Clean(name) != name || !filepath.IsLocal(rel) || len(parts) < 2 || parts[0] != archiveToolsDir {
+			return fmt.Errorf("%w: unexpected entry %q", ErrArchiveInvalid, header.Name)
+		}
+		left, listed := remaining[parts[1]]
+		if !listed {
+			return fmt.Errorf("%w: unexpected entry %q", ErrArchiveInvalid, header.Name)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if header.Size > left {
+				return fmt.Errorf("%w: files of %q are larger than the manifest declares", ErrArchiveInvalid, parts[1])
+			}
+			remaining[parts[1]] = left - header.Size
+		}
+		if err = extractArchiveEntry(tr, header, dest, rel); err != nil {
+			return err
+		}
+	}
+
+	// Links are checked once everything is extracted, because a link may point through links that come later.
+	for _, tool := range manifest.Tools {
+		if err := checkArchiveLinks(filepath.Join(dest, archiveToolsDir, tool.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractArchiveEntry(tr *tar.Reader, header *tar.Header, dest, rel string) error {
+	if err := checkNoSymlinkParents(dest, rel); err != nil {
+		return err
+	}
+	target := filepath.Join(dest, rel)
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, toolDirPerm)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), toolDirPerm); err != nil {
+			return err
+		}
+		// O_EXCL refuses to write through a symbolic link created by an earlier entry.
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, header.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		// The tar reader stops at header.Size, which extractArchive has checked against the manifest.
+		if _, err = io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err = f.Close(); err != nil {
+			return err
+		}
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	case tar.TypeSymlink:
+		if isAbsLink(header.Linkname) {
+			return fmt.Errorf("%w: %s links to absolute path %s", ErrArchiveInvalid, filepath.ToSlash(rel), header.Linkname)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), toolDirPerm); err != nil {
+			return err
+		}
+		return os.Symlink(header.Linkname, target)
+	default:
+		// Hard links, devices and other special files are not part of tool areas.
+		return nil
+	}
+}
+
+// checkNoSymlinkParents ensures no existing parent of rel below base is a symbolic link,
+// so extracting rel cannot write outside base.
+func checkNoSymlinkParents(base, rel string) error {
+	current := base
+	elements := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for _, element := range elements {
+		if element == "." {
+			continue
+		}
+		current = filepath.Join(current, element)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is written through a symbolic link", ErrArchiveInvalid, filepath.ToSlash(rel))
+		}
+	}
+	return nil
+}
+
+// isAbsLink reports whether a link target is absolute on any platform, including Windows paths rooted at the current drive.
+func isAbsLink(target string) bool {
+	return filepath.IsAbs(target) || filepath.VolumeName(target) != "" ||
+		strings.HasPrefix(target, "/") || strings.HasPrefix(target, `\`)
+}
+
+// checkArchiveLinks ensures every symbolic link below root resolves to a path below root.
+func checkArchiveLinks(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		hops := 0
+		if _, err := resolveLinkBelow(root, filepath.Dir(p), p, &hops); err != nil {
+			rel, _ := filepath.Rel(filepath.Dir(filepath.Dir(root)), p)
+			return fmt.Errorf("%w: %s: %w", ErrArchiveInvalid, filepath.ToSlash(rel), err)
+		}
+		return nil
+	})
+}
+
+// resolveLinkBelow resolves the link at link, which is in dir, one path element at a time without leaving root.
+// Elements that do not exist are resolved lexically.
+func resolveLinkBelow(root, dir, link string, hops *int) (string, error) {
+	*hops++
+	if *hops > maxLinkHops {
+		return "", errors.New("too many levels of symbolic links")
+	}
+	target, err := os.Readlink(link)
+	if err != nil {
+		return "", err
+	}
+	if isAbsLink(target) {
+		return "", fmt.Errorf("links to absolute path %s", target)
+	}
+
+	current := dir
+	for _, element := range strings.Split(filepath.ToSlash(target), "/") {
+		switch element {
+		case "", ".":
+			continue
+		case "..":
+			if current == root {
+				return "", fmt.Errorf("link %s points outside the tool directory", target)
+			}
+			current = filepath.Dir(current)
+			continue
+		}
+		next := filepath.Join(current, element)
+		info, lstatErr := os.Lstat(next)
+		if lstatErr != nil && !errors.Is(lstatErr, fs.ErrNotExist) {
+			return "", lstatErr
+		}
+		if lstatErr != nil || info.Mode()&fs.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+		if current, err = resolveLinkBelow(root, current, next, hops); err != nil {
+			return "", err
+		}
+	}
+	return current, nil
+}
+
+// backupToolName returns the tool a directory called "<tool>.bak.<timestamp>" is a backup of.
+func backupToolName(name string) (string, bool) {
+	i := strings.LastIndex(name, archiveBackupInfix)
+	if i <= 0 {
+		return "", false
+	}
+	if _, err := time.Parse(archiveBackupFormat, name[i+len(archiveBackupInfix):]); err != nil {
+		return "", false
+	}
+	return name[:i], true
+}
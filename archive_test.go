@@ -0,0 +1,241 @@
+package sidetable_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func TestWorkspaceExportImport(t *testing.T) {
+	tools := map[string]config.Tool{"ghq": {Run: "ghq"}, "note": {Run: "vim"}}
+	aliases := map[string]config.Alias{"gg": {Tool: "ghq"}}
+	src := setupTestWorkspace(t, tools, aliases)
+
+	ghqDir := filepath.Join(src.AreaDir(), "ghq")
+	require.NoError(t, os.MkdirAll(filepath.Join(ghqDir, "repos"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(ghqDir, "repos", "a.txt"), []byte("a"), 0o600))
+	require.NoError(t, os.Symlink("repos/a.txt", filepath.Join(ghqDir, "link")))
+	require.NoError(t, os.WriteFile(filepath.Join(ghqDir, sidetable.ToolLockFileName), []byte("42\n"), 0o600))
+
+	var archive bytes.Buffer
+	manifest, err := src.Export(&archive, []string{"gg"})
+	require.NoError(t, err)
+	require.Equal(t, sidetable.ArchiveFormatVersion, manifest.Version)
+	require.Len(t, manifest.Tools, 1)
+	require.Equal(t, "ghq", manifest.Tools[0].Name)
+	require.Equal(t, 1, manifest.Tools[0].Files)
+
+	_, err = src.Export(&bytes.Buffer{}, []string{"note"})
+	require.ErrorContains(t, err, "does not exist")
+
+	dest := setupTestWorkspace(t, tools, aliases)
+	result, err := dest.Import(bytes.NewReader(archive.Bytes()), sidetable.ConflictSkip)
+	require.NoError(t, err)
+	require.Len(t, result.Tools, 1)
+	require.Equal(t, sidetable.ImportActionImported, result.Tools[0].Action)
+
+	destGhq := filepath.Join(dest.AreaDir(), "ghq")
+	data, err := os.ReadFile(filepath.Join(destGhq, "repos", "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "a", string(data))
+	link, err := os.Readlink(filepath.Join(destGhq, "link"))
+	require.NoError(t, err)
+	require.Equal(t, "repos/a.txt", link)
+	require.NoFileExists(t, filepath.Join(destGhq, sidetable.ToolLockFileName))
+
+	entries, err := os.ReadDir(dest.AreaDir())
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "staging directory must be removed")
+}
+
+func TestWorkspaceImportConflicts(t *testing.T) {
+	tools := map[string]config.Tool{"ghq": {Run: "ghq"}}
+	ws := setupTestWorkspace(t, tools, nil)
+	dir := filepath.Join(ws.AreaDir(), "ghq")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "state"), []byte("archived"), 0o600))
+
+	var archive bytes.Buffer
+	_, err := ws.Export(&archive, nil)
+	require.NoError(t, err)
+
+	writeState := func(content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "state"), []byte(content), 0o600))
+	}
+	readState := func(path string) string {
+		data, readErr := os.ReadFile(filepath.Join(path, "state"))
+		require.NoError(t, readErr)
+		return string(data)
+	}
+
+	writeState("local")
+	result, err := ws.Import(bytes.NewReader(archive.Bytes()), sidetable.ConflictSkip)
+	require.NoError(t, err)
+	require.True(t, result.ConfigMatches)
+	require.Equal(t, sidetable.ImportActionSkipped, result.Tools[0].Action)
+	require.Equal(t, "local", readState(dir))
+
+	result, err = ws.Import(bytes.NewReader(archive.Bytes()), sidetable.ConflictOverwrite)
+	require.NoError(t, err)
+	require.Equal(t, sidetable.ImportActionOverwritten, result.Tools[0].Action)
+	require.Equal(t, "archived", readState(dir))
+
+	writeState("local")
+	result, err = ws.Import(bytes.NewReader(archive.Bytes()), sidetable.ConflictRename)
+	require.NoError(t, err)
+	require.Equal(t, sidetable.ImportActionRenamed, result.Tools[0].Action)
+	require.Equal(t, "archived", readState(dir))
+	require.Equal(t, "local", readState(result.Tools[0].Backup))
+
+	// Backups are not orphans, so prune keeps them unless they are older than --older-than.
+	status, err := ws.AreaStatus()
+	require.NoError(t, err)
+	require.Empty(t, status.Orphans)
+	require.Len(t, status.Backups, 1)
+	require.Equal(t, result.Tools[0].Backup, status.Backups[0].Path)
+	candidates, err := ws.PruneCandidates(0, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, candidates)
+	candidates, err = ws.PruneCandidates(time.Hour, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	require.Equal(t, sidetable.PruneReasonStale, candidates[0].Reason)
+	require.Equal(t, sidetable.PruneReasonBackup, candidates[1].Reason)
+
+	_, err = ws.Import(bytes.NewReader(archive.Bytes()), "merge")
+	require.ErrorIs(t, err, sidetable.ErrConflictStrategyUnknown)
+
+	// The directory of a tool whose lock is held is never replaced.
+	writeState("local")
+	holdToolLock(t, dir)
+	for _, strategy := range []sidetable.ConflictStrategy{sidetable.ConflictOverwrite, sidetable.ConflictRename} {
+		result, err = ws.Import(bytes.NewReader(archive.Bytes()), strategy)
+		require.NoError(t, err)
+		require.Equal(t, sidetable.ImportActionSkipped, result.Tools[0].Action)
+		require.Contains(t, result.Tools[0].Reason, "locked by")
+		require.Equal(t, "local", readState(dir))
+	}
+}
+
+func TestWorkspaceImportKeepsLinksInsideToolDirectory(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"ghq": {Run: "ghq"}}, nil)
+
+	archive := buildArchive(t, []string{"ghq"}, []tar.Header{
+		{Typeflag: tar.TypeDir, Name: "tools/ghq/bin/"},
+		{Typeflag: tar.TypeSymlink, Name: "tools/ghq/bin/tool", Linkname: "../pkg/tool"},
+		{Typeflag: tar.TypeSymlink, Name: "tools/ghq/current", Linkname: "bin"},
+		{Typeflag: tar.TypeSymlink, Name: "tools/ghq/up", Linkname: "current/.."},
+	})
+	_, err := ws.Import(bytes.NewReader(archive), sidetable.ConflictOverwrite)
+	require.NoError(t, err)
+
+	link, err := os.Readlink(filepath.Join(ws.AreaDir(), "ghq", "bin", "tool"))
+	require.NoError(t, err)
+	require.Equal(t, "../pkg/tool", link)
+}
+
+func TestWorkspaceImportRejectsOversizedManifest(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"ghq": {Run: "ghq"}}, nil)
+
+	manifest := sidetable.ArchiveManifest{
+		Version: sidetable.ArchiveFormatVersion,
+		Tools:   []sidetable.ArchiveTool{{Name: "ghq", Size: sidetable.MaxImportSize + 1}},
+	}
+	_, err := ws.Import(bytes.NewReader(buildArchiveWithManifest(t, manifest, nil)), sidetable.ConflictOverwrite)
+	require.ErrorIs(t, err, sidetable.ErrArchiveInvalid)
+	require.ErrorContains(t, err, "larger than")
+}
+
+func TestWorkspaceImportRejectsUnsafeEntries(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"ghq": {Run: "ghq"}}, nil)
+	outside := t.TempDir()
+
+	tests := map[string][]tar.Header{
+		"parent traversal": {
+			{Typeflag: tar.TypeReg, Name: "tools/ghq/../../evil", Mode: 0o600},
+		},
+		"absolute path": {
+			{Typeflag: tar.TypeReg, Name: "/tmp/evil", Mode: 0o600},
+		},
+		"unlisted tool": {
+			{Typeflag: tar.TypeReg, Name: "tools/other/evil", Mode: 0o600},
+		},
+		"write through symlink": {
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/out", Linkname: "../../.."},
+			{Typeflag: tar.TypeReg, Name: "tools/ghq/out/evil", Mode: 0o600},
+		},
+		"absolute link": {
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/out", Linkname: outside},
+		},
+		"link outside tool directory": {
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/sub/out", Linkname: "../../other"},
+		},
+		"link escaping through another link": {
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/out", Linkname: "here/.."},
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/here", Linkname: "."},
+		},
+		"link loop": {
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/a", Linkname: "b/x"},
+			{Typeflag: tar.TypeSymlink, Name: "tools/ghq/b", Linkname: "a/x"},
+		},
+		"file larger than manifest": {
+			{Typeflag: tar.TypeReg, Name: "tools/ghq/big", Mode: 0o600, Size: 10},
+		},
+	}
+	for name, headers := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive := buildArchive(t, []string{"ghq"}, headers)
+			_, err := ws.Import(bytes.NewReader(archive), sidetable.ConflictOverwrite)
+			require.ErrorIs(t, err, sidetable.ErrArchiveInvalid)
+			require.NoFileExists(t, filepath.Join(outside, "evil"))
+			require.NoDirExists(t, filepath.Join(ws.AreaDir(), "ghq"))
+		})
+	}
+}
+
+// buildArchive writes an uncompressed archive with a manifest for empty tools followed by headers.
+func buildArchive(t *testing.T, tools []string, headers []tar.Header) []byte {
+	t.Helper()
+
+	manifest := sidetable.ArchiveManifest{Version: sidetable.ArchiveFormatVersion}
+	for _, tool := range tools {
+		manifest.Tools = append(manifest.Tools, sidetable.ArchiveTool{Name: tool})
+	}
+	return buildArchiveWithManifest(t, manifest, headers)
+}
+
+// buildArchiveWithManifest writes an uncompressed archive with manifest followed by headers,
+// each with as many bytes of content as its size.
+func buildArchiveWithManifest(t *testing.T, manifest sidetable.ArchiveManifest, headers []tar.Header) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "manifest.json",
+		Mode:     0o644,
+		Size:     int64(len(data)),
+	}))
+	_, err = tw.Write(data)
+	require.NoError(t, err)
+	for _, header := range headers {
+		require.NoError(t, tw.WriteHeader(&header))
+		_, err = tw.Write(bytes.Repeat([]byte("x"), int(header.Size)))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
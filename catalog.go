@@ -2,7 +2,11 @@ package sidetable
 
 import (
 	"errors"
+	"slices"
 	"sort"
+	"strings"
+
+	"github.com/sushichan044/sidetable/internal/config"
 )
 
 // EntryKind describes catalog entry type.
@@ -15,16 +19,89 @@ const (
 
 // Entry is a listable tool or alias.
 type Entry struct {
-	Name         string
+	Name string
+	// Words are the command words used to run the entry, e.g. ["db", "migrate"] for "db.migrate".
+	Words []string
+	// Group is the name of the innermost group containing the entry, or empty at the top level.
+	Group        string
 	Kind         EntryKind
 	Target       string
 	Description  string
 	Instructions string
+	// Run is the program the entry runs on the current platform.
+	// For aliases it is the run of the target tool.
+	Run string
+	// Tags are the entry's tags, sorted.
+	// Aliases carry the tags of their target tool in addition to their own.
+	Tags []string
+	// EnvKeys lists the sorted names of environment variables set by the entry's tool.
+	EnvKeys []string
+	// Available is false when the entry has no tool definition for the current platform.
+	Available bool
+	// Service is true when the entry runs a service tool, which is started in the background with StartService.
+	Service bool
+}
+
+// Group is a command group implied by grouped entry names.
+type Group struct {
+	Name string
+	// Words are the command words of the group, e.g. ["db", "schema"] for "db.schema".
+	Words []string
+	// Parent is the name of the enclosing group, or empty at the top level.
+	Parent      string
+	Description string
+	// Available is true when at least one entry in the group is available.
+	Available bool
 }
 
 // Catalog contains all listable entries.
 type Catalog struct {
 	Entries []Entry
+	// Groups lists every group, sorted by name so that parents come before their children.
+	Groups []Group
+}
+
+// EntryFilter selects catalog entries. Zero fields match every entry.
+type EntryFilter struct {
+	// Kind keeps only entries of this kind.
+	Kind EntryKind
+	// Tags keeps entries that have at least one of these tags.
+	Tags []string
+	// Search keeps entries whose name, description or instructions contain this text, ignoring case.
+	Search string
+}
+
+// Match reports whether entry passes the filter.
+func (f EntryFilter) Match(entry Entry) bool {
+	if f.Kind != "" && entry.Kind != f.Kind {
+		return false
+	}
+	if len(f.Tags) > 0 && !slices.ContainsFunc(f.Tags, func(tag string) bool {
+		return slices.Contains(entry.Tags, tag)
+	}) {
+		return false
+	}
+	if f.Search != "" {
+		query := strings.ToLower(f.Search)
+		fields := []string{entry.Name, entry.Description, entry.Instructions}
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return strings.Contains(strings.ToLower(field), query)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the entries that pass f, in catalog order.
+func (c *Catalog) Filter(f EntryFilter) []Entry {
+	entries := make([]Entry, 0, len(c.Entries))
+	for _, entry := range c.Entries {
+		if f.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
 }
 
 // Catalog returns tools and aliases available in this workspace.
@@ -33,14 +110,23 @@ func (w *Workspace) Catalog() (*Catalog, error) {
 		return nil, errors.New("workspace is not initialized")
 	}
 
+	platform := config.CurrentPlatform()
+
 	entries := make([]Entry, 0, len(w.config.Tools)+len(w.config.Aliases))
 	for _, name := range w.config.ToolNames() {
-		tool := w.config.Tools[name]
+		tool := w.config.Tools[name].ForPlatform(platform)
 		entries = append(entries, Entry{
 			Name:         name,
+			Words:        w.config.EntryWords(name),
+			Group:        w.parentGroup(name),
 			Kind:         EntryKindTool,
 			Description:  tool.Description,
 			Instructions: tool.Instructions,
+			Tags:         mergeTags(tool.Tags),
+			Run:          tool.Run,
+			EnvKeys:      sortedKeys(tool.Env),
+			Available:    tool.Run != "",
+			Service:      tool.Service,
 		})
 	}
 
@@ -51,13 +137,74 @@ func (w *Workspace) Catalog() (*Catalog, error) {
 	sort.Strings(aliasNames)
 	for _, name := range aliasNames {
 		alias := w.config.Aliases[name]
+		tool := w.config.Tools[alias.Tool].ForPlatform(platform)
 		entries = append(entries, Entry{
 			Name:        name,
+			Words:       w.config.EntryWords(name),
+			Group:       w.parentGroup(name),
 			Kind:        EntryKindAlias,
 			Target:      alias.Tool,
 			Description: alias.Description,
+			Tags:        mergeTags(alias.Tags, tool.Tags),
+			Run:         tool.Run,
+			EnvKeys:     sortedKeys(tool.Env),
+			Available:   tool.Run != "",
+			Service:     tool.Service,
+		})
+	}
+
+	return &Catalog{Entries: entries, Groups: w.catalogGroups(entries)}, nil
+}
+
+func (w *Workspace) catalogGroups(entries []Entry) []Group {
+	available := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.Available {
+			continue
+		}
+		for _, group := range w.config.ParentGroups(entry.Name) {
+			available[group] = true
+		}
+	}
+
+	names := w.config.GroupNames()
+	groups := make([]Group, 0, len(names))
+	for _, name := range names {
+		groups = append(groups, Group{
+			Name:        name,
+			Words:       w.config.EntryWords(name),
+			Parent:      w.parentGroup(name),
+			Description: w.config.Groups[name].Description,
+			Available:   available[name],
 		})
 	}
+	return groups
+}
 
-	return &Catalog{Entries: entries}, nil
+// parentGroup returns the innermost group containing name.
+func (w *Workspace) parentGroup(name string) string {
+	parents := w.config.ParentGroups(name)
+	if len(parents) == 0 {
+		return ""
+	}
+	return parents[len(parents)-1]
+}
+
+// mergeTags returns the sorted union of tag lists.
+func mergeTags(lists ...[]string) []string {
+	tags := make([]string, 0)
+	for _, list := range lists {
+		tags = append(tags, list...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+)
+
+var againCmd = &cobra.Command{
+	Use:   "again [tool-or-alias]",
+	Short: "Run the last invocation in this workspace again",
+	Long: `Run the most recent invocation recorded in this workspace again, with the same arguments.
+With a tool or alias name, the most recent invocation of that entry is used.
+
+Invocations whose arguments were redacted as secrets cannot be replayed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		entry := ""
+		if len(args) == 1 {
+			entry = args[0]
+		}
+		last, err := workspace.LastInvocation(entry)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.ErrOrStderr(), strings.Join(append([]string{"sidetable", last.Entry}, last.UserArgs...), " "))
+		return workspace.Run(context.Background(), last.Entry, last.UserArgs, sidetable.InvokeOptions{
+			Stdout:         cmd.OutOrStdout(),
+			Stderr:         cmd.ErrOrStderr(),
+			Origin:         sidetable.OriginCLI,
+			Timeout:        runTimeout,
+			ForwardSignals: true,
+			Exec:           cliExecMode(),
+			LockNoWait:     runNoWait,
+			LockTimeout:    runLockTimeout,
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(againCmd)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+var (
+	aliasAddPrepend     []string
+	aliasAddAppend      []string
+	aliasAddDescription string
+	aliasAddTags        []string
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Add, remove or rename aliases in the configuration",
+	Long: `Add, remove or rename aliases in the sidetable configuration.
+
+The config file is edited in place: comments and key order are preserved.
+The result is validated before it is written.`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:     "add <name> <tool>",
+	Short:   "Add an alias",
+	Example: `  sidetable alias add gg ghq --prepend get --prepend -u`,
+	Args:    cobra.ExactArgs(2), //nolint:mnd // alias name and target tool
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alias := config.Alias{
+			Tool:        args[1],
+			Args:        config.Args{Prepend: aliasAddPrepend, Append: aliasAddAppend},
+			Description: aliasAddDescription,
+			Tags:        aliasAddTags,
+		}
+
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.AddAlias(source, args[0], alias)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Added alias %s to %s\n", args[0], path)
+		return nil
+	},
+}
+
+var aliasRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove an alias",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.RemoveAlias(source, args[0])
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed alias %s from %s\n", args[0], path)
+		return nil
+	},
+}
+
+var aliasRenameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Rename an alias",
+	Args:    cobra.ExactArgs(2), //nolint:mnd // old and new name
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.RenameAlias(source, args[0], args[1])
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Renamed alias %s to %s in %s\n", args[0], args[1], path)
+		return nil
+	},
+}
+
+func init() {
+	flags := aliasAddCmd.Flags()
+	flags.StringArrayVar(&aliasAddPrepend, "prepend", nil, "argument inserted before user arguments (repeatable)")
+	flags.StringArrayVar(&aliasAddAppend, "append", nil, "argument inserted after user arguments (repeatable)")
+	flags.StringVar(&aliasAddDescription, "description", "", "description shown in sidetable list")
+	flags.StringArrayVar(&aliasAddTags, "tag", nil, "tag (repeatable)")
+
+	aliasCmd.AddCommand(aliasAddCmd, aliasRemoveCmd, aliasRenameCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
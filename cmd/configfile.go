@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/fileutil"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+// updateConfigFile applies edit to the resolved config file and writes the result
+// only when it still validates, or, for a config that is already invalid, when the edit adds no new issues.
+// The latter lets a config with several problems, such as tools named after newer builtin commands,
+// be repaired one edit at a time.
+func updateConfigFile(edit func(source []byte) ([]byte, error)) (string, error) {
+	path, err := findConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := edit(source)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = config.Parse(updated, path, configLoadOptions()...); err != nil && addsIssues(source, path, err) {
+		return "", errors.Join(fmt.Errorf("config was not changed because the result would be invalid: %s", path), err)
+	}
+
+	return path, writeConfigFile(path, updated)
+}
+
+// writeConfigFile replaces the config at path with data.
+// A trusted project-level config stays trusted, since the change was made through sidetable itself.
+func writeConfigFile(path string, data []byte) error {
+	trusted, err := trust.IsTrusted(path)
+	if err != nil {
+		return err
+	}
+	if err = fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return err
+	}
+	if trusted {
+		_, err = trust.Add(path, time.Now())
+	}
+	return err
+}
+
+// addsIssues reports whether err, the result of parsing an edited config, has issues the source did not have.
+func addsIssues(source []byte, path string, err error) bool {
+	_, sourceErr := config.Parse(source, path, configLoadOptions()...)
+	if sourceErr == nil {
+		return true
+	}
+	known := make(map[string]bool)
+	for _, diag := range config.Diagnostics(sourceErr) {
+		known[diag.Path+"\x00"+diag.Message] = true
+	}
+	for _, diag := range config.Diagnostics(err) {
+		if !known[diag.Path+"\x00"+diag.Message] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// areaDirPerm is used when creating the tool area with --create.
+const areaDirPerm = 0o755
+
+var (
+	dirRoot   bool
+	dirCreate bool
+	dirJSON   bool
+)
+
+var dirCmd = &cobra.Command{
+	Use:     "dir [entry]",
+	Aliases: []string{"path"},
+	Short:   "Print the directory of a tool, the tool area, or the workspace root",
+	Long: `Print the directory of a tool or alias ({{.ToolDir}}).
+Aliases resolve to the directory of their target tool.
+
+Without an entry, the tool area (the configured directory under the workspace root) is printed.
+With --root, the workspace root ({{.WorkspaceRoot}}) is printed instead.
+
+  cd "$(sidetable dir ghq --create)"`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		workspace, err := openWorkspace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		catalog, err := workspace.Catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names := make([]string, 0, len(catalog.Entries))
+		for _, entry := range catalog.Entries {
+			names = append(names, entry.Name+"\t"+entry.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dirRoot && len(args) > 0 {
+			return errors.New("--root cannot be combined with an entry")
+		}
+
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		dirs := entryDirs{
+			WorkspaceRoot: workspace.Root(),
+			Area:          workspace.AreaDir(),
+		}
+		target := dirs.Area
+		switch {
+		case len(args) == 1:
+			if dirCreate {
+				dirs.ToolDir, err = workspace.EnsureToolDir(args[0])
+			} else {
+				dirs.ToolDir, err = workspace.ToolDir(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			target = dirs.ToolDir
+		case dirRoot:
+			target = dirs.WorkspaceRoot
+		case dirCreate:
+			if err = os.MkdirAll(dirs.Area, areaDirPerm); err != nil {
+				return err
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		if dirJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(dirs)
+		}
+		_, err = fmt.Fprintln(out, target)
+		return err
+	},
+}
+
+type entryDirs struct {
+	WorkspaceRoot string `json:"workspace_root"`
+	Area          string `json:"area"`
+	ToolDir       string `json:"tool_dir,omitempty"`
+}
+
+func init() {
+	dirCmd.Flags().BoolVar(&dirRoot, "root", false, "print the workspace root")
+	dirCmd.Flags().BoolVarP(&dirCreate, "create", "c", false, "create the directory if it does not exist")
+	dirCmd.Flags().BoolVar(&dirJSON, "json", false, "print the workspace root, tool area and tool directory as JSON")
+	rootCmd.AddCommand(dirCmd)
+}
@@ -0,0 +1,57 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirCommand(t *testing.T) {
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  ghq:\n    run: ghq\naliases:\n  gg:\n    tool: ghq\n")
+	root := t.TempDir()
+	t.Chdir(root)
+	// Resolve symlinks such as /tmp -> /private/tmp on macOS.
+	root, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		dirRoot = false
+		dirCreate = false
+		dirJSON = false
+	})
+
+	run := func(args ...string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		dirCmd.SetOut(&buf)
+		require.NoError(t, dirCmd.RunE(dirCmd, args))
+		return buf.String()
+	}
+
+	toolDir := filepath.Join(root, ".private", "ghq")
+	require.Equal(t, toolDir+"\n", run("gg"))
+	require.Equal(t, filepath.Join(root, ".private")+"\n", run())
+	require.NoDirExists(t, toolDir)
+
+	dirCreate = true
+	require.Equal(t, toolDir+"\n", run("ghq"))
+	require.DirExists(t, toolDir)
+	dirCreate = false
+
+	dirRoot = true
+	require.Equal(t, root+"\n", run())
+	require.Error(t, dirCmd.RunE(dirCmd, []string{"ghq"}))
+	dirRoot = false
+
+	dirJSON = true
+	var dirs entryDirs
+	require.NoError(t, json.Unmarshal([]byte(run("ghq")), &dirs))
+	require.Equal(t, entryDirs{WorkspaceRoot: root, Area: filepath.Join(root, ".private"), ToolDir: toolDir}, dirs)
+
+	require.Error(t, dirCmd.RunE(dirCmd, []string{"missing"}))
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorSkip = "skip"
+
+	// doctorTrackedPreview is the number of tracked files shown in a warning.
+	doctorTrackedPreview = 3
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the workspace for common problems",
+	Long: `Check the workspace for common problems.
+
+The checks cover:
+  - config: the config loads without warnings
+  - git: the tool area is ignored by the enclosing git repository and none of its files are tracked
+
+Exits with a non-zero status when a check warns.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		path, err := findConfigPath()
+		if err != nil {
+			return err
+		}
+		checks := doctorConfigChecks(path, workspace.Warnings())
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		gitChecks, err := doctorGitChecks(ctx, workspace)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, gitChecks...)
+
+		if err = writeDoctorChecks(cmd.OutOrStdout(), checks); err != nil {
+			return err
+		}
+
+		problems := 0
+		for _, check := range checks {
+			if check.Status == doctorWarn {
+				problems++
+			}
+		}
+		if problems > 0 {
+			return fmt.Errorf("doctor found %d problem(s)", problems)
+		}
+		return nil
+	},
+}
+
+type doctorCheck struct {
+	Status  string
+	Name    string
+	Message string
+}
+
+func doctorConfigChecks(path string, warnings []string) []doctorCheck {
+	checks := []doctorCheck{{Status: doctorOK, Name: "config", Message: "loaded " + path}}
+	for _, warning := range warnings {
+		checks = append(checks, doctorCheck{Status: doctorWarn, Name: "config", Message: warning})
+	}
+	return checks
+}
+
+func doctorGitChecks(ctx context.Context, workspace *sidetable.Workspace) ([]doctorCheck, error) {
+	status, err := workspace.GitStatus(ctx)
+	if errors.Is(err, exec.ErrNotFound) {
+		return []doctorCheck{{Status: doctorSkip, Name: "git", Message: "git not found; cannot check the tool area"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !status.Repository {
+		return []doctorCheck{{Status: doctorSkip, Name: "git", Message: "workspace is not inside a git repository"}}, nil
+	}
+
+	area := workspace.AreaDir()
+	checks := []doctorCheck{{Status: doctorOK, Name: "git", Message: area + " is ignored"}}
+	if !status.Ignored {
+		checks[0] = doctorCheck{
+			Status: doctorWarn,
+			Name:   "git",
+			Message: fmt.Sprintf(
+				"%s is not ignored; set \"git_exclude: true\" in the config or add %q to %s",
+				area, status.Pattern, status.ExcludeFile,
+			),
+		}
+	}
+
+	if len(status.Tracked) > 0 {
+		preview := status.Tracked
+		if len(preview) > doctorTrackedPreview {
+			preview = append(preview[:doctorTrackedPreview:doctorTrackedPreview], "...")
+		}
+		rel := strings.Trim(status.Pattern, "/")
+		checks = append(checks, doctorCheck{
+			Status: doctorWarn,
+			Name:   "git",
+			Message: fmt.Sprintf(
+				"%d file(s) in %s are tracked (%s); untrack them with \"git -C %s rm -r --cached %s\"",
+				len(status.Tracked), area, strings.Join(preview, ", "), status.WorkTree, filepath.FromSlash(rel),
+			),
+		})
+	}
+	return checks, nil
+}
+
+func writeDoctorChecks(w io.Writer, checks []doctorCheck) error {
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Status
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Check
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Message
+	)
+
+	rows := make([][]string, 0, len(checks))
+	for _, check := range checks {
+		rows = append(rows, []string{check.Status, check.Name, check.Message})
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,69 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorCommandGitChecks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  ghq:\n    run: ghq\n")
+	root := t.TempDir()
+	t.Chdir(root)
+
+	var out bytes.Buffer
+	doctorCmd.SetOut(&out)
+
+	require.NoError(t, doctorCmd.RunE(doctorCmd, nil))
+	require.Contains(t, out.String(), "workspace is not inside a git repository")
+
+	gitInit := exec.Command("git", "init", "--quiet", root)
+	require.NoError(t, gitInit.Run())
+
+	out.Reset()
+	require.EqualError(t, doctorCmd.RunE(doctorCmd, nil), "doctor found 1 problem(s)")
+	require.Contains(t, out.String(), "is not ignored")
+	require.Contains(t, out.String(), `"/.private/"`)
+
+	workspace, err := openWorkspace()
+	require.NoError(t, err)
+	_, err = workspace.EnsureGitExclude()
+	require.NoError(t, err)
+
+	out.Reset()
+	require.NoError(t, doctorCmd.RunE(doctorCmd, nil))
+	require.Contains(t, out.String(), filepath.Join(root, ".private")+" is ignored")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".private"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".private", "a"), []byte("a"), 0o600))
+	gitAdd := exec.Command("git", "-C", root, "add", "--force", ".private/a")
+	require.NoError(t, gitAdd.Run())
+
+	out.Reset()
+	require.EqualError(t, doctorCmd.RunE(doctorCmd, nil), "doctor found 1 problem(s)")
+	require.Contains(t, out.String(), "1 file(s)")
+	require.Contains(t, out.String(), ".private/a")
+}
+
+func TestDoctorCommandSkipsGitChecksWithoutGit(t *testing.T) {
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  ghq:\n    run: ghq\n")
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	t.Chdir(root)
+	t.Setenv("PATH", t.TempDir())
+
+	var out bytes.Buffer
+	doctorCmd.SetOut(&out)
+
+	require.NoError(t, doctorCmd.RunE(doctorCmd, nil))
+	require.Contains(t, out.String(), "git not found")
+}
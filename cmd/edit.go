@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+// editIssuePrefix marks the comment lines edit adds to report issues.
+// They are stripped before the config is saved.
+const editIssuePrefix = "# [sidetable] "
+
+// defaultEditor is used when neither VISUAL nor EDITOR is set.
+const defaultEditor = "vi"
+
+var errEditAborted = errors.New("edit aborted: config has errors and was left unchanged")
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the sidetable configuration and validate it before saving",
+	Long: `Open a copy of the sidetable configuration in $VISUAL or $EDITOR.
+
+When the editor exits, the edited config is validated.
+A valid config atomically replaces the original file.
+When issues are found, the editor is reopened with the issues listed as comments at the top of the file.
+Exit the editor without changing the file to give up; the original config is left untouched.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		path, err := findConfigPath()
+		if err != nil {
+			return err
+		}
+
+		editor := editorCommand()
+		return editConfig(path, func(file string) error {
+			//nolint:gosec // the editor is chosen by the user
+			c := exec.CommandContext(cmd.Context(), editor[0], append(editor[1:], file)...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			return c.Run()
+		}, cmd.OutOrStdout())
+	},
+}
+
+// editorCommand returns the editor command line from VISUAL or EDITOR.
+func editorCommand() []string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(key)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{defaultEditor}
+}
+
+// editConfig lets edit modify a temporary copy of the config at path until it validates,
+// then atomically replaces path with the result.
+func editConfig(path string, edit func(file string) error, out io.Writer) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "sidetable-config-*.yml")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	content := original
+	for {
+		if err = os.WriteFile(tmpPath, content, 0o600); err != nil {
+			return err
+		}
+		if err = edit(tmpPath); err != nil {
+			return fmt.Errorf("editor failed: %w", err)
+		}
+
+		edited, readErr := os.ReadFile(tmpPath)
+		if readErr != nil {
+			return readErr
+		}
+		if bytes.Equal(edited, content) {
+			if bytes.Equal(edited, original) {
+				fmt.Fprintln(out, "No changes made")
+				return nil
+			}
+			return errEditAborted
+		}
+
+		_, loadErr := config.Load(tmpPath, validateLoadOptions()...)
+		if loadErr == nil {
+			if err = writeConfigFile(path, stripEditIssues(edited)); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Saved %s\n", path)
+			return nil
+		}
+
+		content = withEditIssues(edited, loadErr)
+	}
+}
+
+// withEditIssues replaces the issue comments at the top of source with the issues in err.
+func withEditIssues(source []byte, err error) []byte {
+	issues := make([]string, 0)
+	diags := config.Diagnostics(err)
+	if len(diags) == 0 {
+		issues = append(issues, err.Error())
+	}
+
+	header := []string{
+		"The config has issues and was not saved.",
+		"Fix them and save, or exit without changes to abort.",
+	}
+	// Positions refer to source, so shift them by the difference between the old and new header sizes.
+	lineOffset := len(header) + len(diags) + len(issues) - editIssueLines(source)
+	for _, diag := range diags {
+		issue := diag.Message
+		if diag.Path != "" {
+			issue = diag.Path + ": " + issue
+		}
+		if diag.Line > 0 {
+			issue = fmt.Sprintf("%d:%d: %s", diag.Line+lineOffset, diag.Column, issue)
+		}
+		issues = append(issues, issue)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range header {
+		buf.WriteString(editIssuePrefix + line + "\n")
+	}
+	for _, issue := range issues {
+		buf.WriteString(editIssuePrefix + "- " + singleLine(issue) + "\n")
+	}
+	buf.Write(stripEditIssues(source))
+	return buf.Bytes()
+}
+
+// stripEditIssues removes the issue comments added by withEditIssues.
+func stripEditIssues(source []byte) []byte {
+	rest := source
+	for bytes.HasPrefix(rest, []byte(editIssuePrefix)) {
+		_, after, found := bytes.Cut(rest, []byte("\n"))
+		if !found {
+			return nil
+		}
+		rest = after
+	}
+	return rest
+}
+
+// editIssueLines counts the issue comment lines at the top of source.
+func editIssueLines(source []byte) int {
+	return bytes.Count(source[:len(source)-len(stripEditIssues(source))], []byte("\n"))
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+}
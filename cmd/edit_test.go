@@ -0,0 +1,123 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const editValidConfig = `version: 1
+# keep me
+directory: .sidetable
+tools: {}
+`
+
+func writeEditConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// scriptedEditor returns an edit function that applies each step to the file in turn.
+func scriptedEditor(t *testing.T, steps ...func(current string) string) (func(string) error, *[]string) {
+	t.Helper()
+	seen := make([]string, 0, len(steps))
+	return func(file string) error {
+		require.NotEmpty(t, steps, "editor opened more often than expected")
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		seen = append(seen, string(data))
+		next := steps[0](string(data))
+		steps = steps[1:]
+		return os.WriteFile(file, []byte(next), 0o600)
+	}, &seen
+}
+
+func TestEditConfigSavesValidChanges(t *testing.T) {
+	path := writeEditConfig(t, editValidConfig)
+	edit, _ := scriptedEditor(t, func(current string) string {
+		return strings.Replace(current, "tools: {}", "tools:\n  hello:\n    run: echo", 1)
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, editConfig(path, edit, &out))
+	require.Contains(t, out.String(), "Saved "+path)
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(saved), "# keep me\n")
+	require.Contains(t, string(saved), "run: echo")
+}
+
+func TestEditConfigReopensWithIssuesUntilValid(t *testing.T) {
+	path := writeEditConfig(t, editValidConfig)
+	edit, seen := scriptedEditor(
+		t,
+		func(current string) string {
+			return strings.Replace(current, "tools: {}", "tools:\n  hello:\n    runn: echo", 1)
+		},
+		func(current string) string {
+			return strings.Replace(current, "    runn:", "    run:", 1)
+		},
+	)
+
+	var out bytes.Buffer
+	require.NoError(t, editConfig(path, edit, &out))
+	require.Len(t, *seen, 2)
+
+	reopened := (*seen)[1]
+	require.True(t, strings.HasPrefix(reopened, editIssuePrefix+"The config has issues and was not saved.\n"))
+	// Two header lines and two issues push the offending key from line 6 to line 10.
+	require.Contains(t, reopened, editIssuePrefix+`- 9:3: tools["hello"].run: tool run is required`)
+	require.Contains(t, reopened, editIssuePrefix+`- 10:5: tools["hello"].runn: unknown field "runn" (did you mean "run"?)`)
+	require.Equal(t, "    runn: echo", strings.Split(reopened, "\n")[9])
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(saved), editIssuePrefix)
+	require.True(t, strings.HasPrefix(string(saved), "version: 1\n"))
+}
+
+func TestEditConfigAbortsWhenIssuesAreLeftUnchanged(t *testing.T) {
+	path := writeEditConfig(t, editValidConfig)
+	edit, _ := scriptedEditor(
+		t,
+		func(string) string { return "directory: [" },
+		func(current string) string { return current },
+	)
+
+	err := editConfig(path, edit, &bytes.Buffer{})
+	require.ErrorIs(t, err, errEditAborted)
+
+	saved, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	require.Equal(t, editValidConfig, string(saved))
+}
+
+func TestEditConfigWithoutChanges(t *testing.T) {
+	path := writeEditConfig(t, editValidConfig)
+	edit, _ := scriptedEditor(t, func(current string) string { return current })
+
+	var out bytes.Buffer
+	require.NoError(t, editConfig(path, edit, &out))
+	require.Equal(t, "No changes made\n", out.String())
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	require.Equal(t, []string{"code", "--wait"}, editorCommand())
+
+	t.Setenv("VISUAL", "nvim")
+	require.Equal(t, []string{"nvim"}, editorCommand())
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	require.Equal(t, []string{"vi"}, editorCommand())
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/redact"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+const (
+	explainFormatText = "text"
+	explainFormatJSON = "json"
+)
+
+var explainFormat string
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <entry> [args...]",
+	Short: "Show the fully resolved invocation of a tool or alias without running it",
+	Long: `Show how sidetable would run a tool or alias, without running it.
+
+The output shows the program and where it was found, every argument labeled with where it came from,
+and the environment variables that differ from the current environment.
+Values that look like secrets are redacted.`,
+	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		workspace, err := openWorkspace()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		catalog, err := workspace.Catalog()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names := make([]string, 0, len(catalog.Entries))
+		for _, entry := range catalog.Entries {
+			names = append(names, entry.Name+"\t"+entry.Description)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		inv, resolveErr := workspace.Resolve(args[0], args[1:])
+		if _, notFound := sidetable.AsProgramNotFoundError(resolveErr); resolveErr != nil && !notFound {
+			return resolveErr
+		}
+
+		explanation := newExplanation(args[0], inv, os.Environ(), resolveErr)
+		switch explainFormat {
+		case explainFormatText:
+			err = explanation.writeText(cmd.OutOrStdout())
+		case explainFormatJSON:
+			err = explanation.writeJSON(cmd.OutOrStdout())
+		default:
+			return fmt.Errorf("unknown format %q: must be one of text, json", explainFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		return resolveErr
+	},
+}
+
+type explanation struct {
+	Entry      string               `json:"entry"`
+	Tool       string               `json:"tool"`
+	Alias      string               `json:"alias,omitempty"`
+	Program    string               `json:"program"`
+	Path       string               `json:"path,omitempty"`
+	SearchPath []string             `json:"search_path,omitempty"`
+	Args       []explainedArg       `json:"args"`
+	Env        []explainedEnvChange `json:"env"`
+	Error      string               `json:"error,omitempty"`
+}
+
+type explainedArg struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type explainedEnvChange struct {
+	Key      string `json:"key"`
+	Change   string `json:"change"`
+	Value    string `json:"value,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+func newExplanation(entry string, inv sidetable.Invocation, parentEnv []string, resolveErr error) explanation {
+	e := explanation{
+		Entry:      entry,
+		Tool:       inv.ToolName,
+		Alias:      inv.AliasName,
+		Program:    inv.Program,
+		Path:       inv.Path,
+		SearchPath: inv.SearchPath,
+		Args:       make([]explainedArg, 0, len(inv.Args)),
+		Env:        make([]explainedEnvChange, 0),
+	}
+	if resolveErr != nil {
+		e.Error = resolveErr.Error()
+	}
+
+	for i, arg := range redact.Args(inv.Args) {
+		e.Args = append(e.Args, explainedArg{Value: arg, Source: string(inv.ArgSources[i])})
+	}
+	for _, change := range sidetable.EnvDiff(parentEnv, inv.Env) {
+		e.Env = append(e.Env, explainedEnvChange{
+			Key:      change.Key,
+			Change:   string(change.Kind),
+			Value:    redact.Value(change.Key, change.Value),
+			Previous: redact.Value(change.Key, change.Previous),
+		})
+	}
+
+	return e
+}
+
+func (e explanation) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+func (e explanation) writeText(w io.Writer) error {
+	entry := e.Entry
+	if e.Alias != "" {
+		entry += " (alias of " + e.Tool + ")"
+	}
+	path := e.Path
+	if path == "" {
+		path = "(not found)"
+	}
+
+	header := spacing.NewFormatter(spacing.Column(), spacing.MinSpacing(1), spacing.Column())
+	rows := [][]string{
+		{"Entry:", entry},
+		{"Program:", e.Program},
+		{"Path:", path},
+	}
+	for i, dir := range e.SearchPath {
+		label := ""
+		if i == 0 {
+			label = "Search:"
+		}
+		rows = append(rows, []string{label, dir})
+	}
+	if err := header.AddRows(rows...); err != nil {
+		return err
+	}
+	if err := header.Println(w); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Arguments:")
+	if len(e.Args) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	} else {
+		args := spacing.NewFormatter(
+			spacing.MinSpacing(2), //nolint:mnd // indentation
+			spacing.Column(),      // Index
+			spacing.MinSpacing(2), //nolint:mnd // fixed spacing value for readability
+			spacing.Column(),      // Value
+			spacing.MinSpacing(4), //nolint:mnd // fixed spacing value for readability
+			spacing.Column(),      // Source
+		)
+		for i, arg := range e.Args {
+			if err := args.AddRows([]string{strconv.Itoa(i), arg.Value, arg.Source}); err != nil {
+				return err
+			}
+		}
+		if err := args.Println(w); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Environment changes:")
+	if len(e.Env) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, change := range e.Env {
+		switch sidetable.EnvChangeKind(change.Change) {
+		case sidetable.EnvChangeAdded:
+			fmt.Fprintf(w, "  + %s=%s\n", change.Key, change.Value)
+		case sidetable.EnvChangeModified:
+			fmt.Fprintf(w, "  ~ %s=%s (was %s)\n", change.Key, change.Value, change.Previous)
+		case sidetable.EnvChangeRemoved:
+			fmt.Fprintf(w, "  - %s (was %s)\n", change.Key, change.Previous)
+		}
+	}
+	return nil
+}
+
+func init() {
+	explainCmd.Flags().SetInterspersed(false)
+	explainCmd.Flags().StringVarP(
+		&explainFormat,
+		"format",
+		"f",
+		explainFormatText,
+		"output format (text, json)",
+	)
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,80 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+)
+
+func testInvocation() sidetable.Invocation {
+	return sidetable.Invocation{
+		ToolName:   "ghq",
+		AliasName:  "gg",
+		Program:    "ghq",
+		Path:       "/usr/bin/ghq",
+		Args:       []string{"get", "--token=abc", "repo"},
+		ArgSources: []sidetable.ArgSource{sidetable.ArgSourceAliasPrepend, sidetable.ArgSourceUser, sidetable.ArgSourceUser},
+		Env:        []string{"PATH=/bin", "GHQ_ROOT=/w/.private/ghq", "GITHUB_TOKEN=secret"},
+	}
+}
+
+func TestExplanationText(t *testing.T) {
+	e := newExplanation("gg", testInvocation(), []string{"PATH=/bin"}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, e.writeText(&buf))
+	out := buf.String()
+
+	require.Contains(t, out, "Entry:   gg (alias of ghq)\n")
+	require.Contains(t, out, "Path:    /usr/bin/ghq\n")
+	require.Contains(t, out, "  0  get                   alias prepend\n")
+	require.Contains(t, out, "  1  --token=[REDACTED]    user\n")
+	require.Contains(t, out, "  + GHQ_ROOT=/w/.private/ghq\n")
+	require.Contains(t, out, "  + GITHUB_TOKEN=[REDACTED]\n")
+	require.NotContains(t, out, "secret")
+	require.NotContains(t, out, "abc")
+}
+
+func TestExplanationRedactsSeparateSecretFlag(t *testing.T) {
+	inv := testInvocation()
+	inv.Args = []string{"get", "--token", "abc", "repo"}
+	inv.ArgSources = []sidetable.ArgSource{
+		sidetable.ArgSourceAliasPrepend,
+		sidetable.ArgSourceUser,
+		sidetable.ArgSourceUser,
+		sidetable.ArgSourceUser,
+	}
+	e := newExplanation("gg", inv, []string{"PATH=/bin"}, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, e.writeText(&buf))
+	require.Contains(t, buf.String(), "  2  [REDACTED]    user\n")
+	require.Contains(t, buf.String(), "  3  repo          user\n")
+	require.NotContains(t, buf.String(), "abc")
+}
+
+func TestExplanationJSON(t *testing.T) {
+	inv := testInvocation()
+	inv.Path = ""
+	e := newExplanation("gg", inv, []string{"PATH=/bin"}, errors.New("program not found"))
+
+	var buf bytes.Buffer
+	require.NoError(t, e.writeJSON(&buf))
+
+	var decoded explanation
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, "ghq", decoded.Tool)
+	require.Equal(t, "gg", decoded.Alias)
+	require.Empty(t, decoded.Path)
+	require.Equal(t, "program not found", decoded.Error)
+	require.Equal(t, explainedArg{Value: "get", Source: "alias prepend"}, decoded.Args[0])
+	require.Len(t, decoded.Env, 2)
+	require.False(t, strings.Contains(buf.String(), "secret"))
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+)
+
+// exportFilePerm is used for archives written with --output.
+const exportFilePerm = 0o600
+
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [tool-or-alias...]",
+	Short: "Write tool directories to a tar.gz archive",
+	Long: `Write tool directories to a gzip-compressed tar archive for use with "sidetable import".
+
+Without arguments, every configured tool with an existing directory is exported.
+Aliases export the directory of their target tool.
+The archive starts with a manifest listing the tools, a hash of the config and timestamps.
+Symbolic links are archived as links and never followed.
+
+  sidetable export ghq note -o area.tar.gz`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		if exportOutput == "-" {
+			_, err = workspace.Export(cmd.OutOrStdout(), args)
+			return err
+		}
+
+		manifest, err := exportToFile(workspace, exportOutput, args)
+		if err != nil {
+			return err
+		}
+		for _, tool := range manifest.Tools {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %s (%d files, %s)\n", tool.Name, tool.Files, formatBytes(tool.Size))
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", exportOutput)
+		return nil
+	},
+}
+
+// exportToFile writes the archive to path, removing the partial file when the export fails.
+func exportToFile(workspace *sidetable.Workspace, path string, names []string) (*sidetable.ArchiveManifest, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, exportFilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := workspace.Export(f, names)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, errors.Join(err, os.Remove(path))
+	}
+	return manifest, nil
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", `archive path, or "-" for standard output`)
+	rootCmd.AddCommand(exportCmd)
+}
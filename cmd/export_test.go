@@ -0,0 +1,47 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportCommands(t *testing.T) {
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  ghq:\n    run: ghq\n")
+	root := t.TempDir()
+	t.Chdir(root)
+	require.NoError(t, os.MkdirAll(filepath.Join(".private", "ghq"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(".private", "ghq", "state"), []byte("archived"), 0o600))
+
+	archive := filepath.Join(t.TempDir(), "area.tar.gz")
+	exportOutput = archive
+	t.Cleanup(func() {
+		exportOutput = "-"
+		importConflict = "skip"
+	})
+
+	var stderr bytes.Buffer
+	exportCmd.SetErr(&stderr)
+	require.NoError(t, exportCmd.RunE(exportCmd, nil))
+	require.Contains(t, stderr.String(), "Exported ghq (1 files, 8 B)")
+	require.FileExists(t, archive)
+
+	require.NoError(t, os.WriteFile(filepath.Join(".private", "ghq", "state"), []byte("local"), 0o600))
+
+	var out bytes.Buffer
+	importCmd.SetOut(&out)
+	require.NoError(t, importCmd.RunE(importCmd, []string{archive}))
+	require.Equal(t, "Skipped ghq (already exists)\n", out.String())
+
+	out.Reset()
+	importConflict = "rename"
+	require.NoError(t, importCmd.RunE(importCmd, []string{archive}))
+	require.Contains(t, out.String(), "existing directory moved to")
+	data, err := os.ReadFile(filepath.Join(".private", "ghq", "state"))
+	require.NoError(t, err)
+	require.Equal(t, "archived", string(data))
+}
@@ -0,0 +1,10 @@
+package cmd
+
+// Output formats and the time layout shared by commands that print reports about the workspace.
+const (
+	formatText = "text"
+	formatJSON = "json"
+
+	// reportTimeLayout formats times in text reports, in local time.
+	reportTimeLayout = "2006-01-02 15:04"
+)
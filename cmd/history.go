@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+const (
+	historyStatusOK     = "ok"
+	historyStatusFailed = "failed"
+
+	historyDateLayout = "2006-01-02"
+
+	// historyDurationPrecision rounds durations in text output.
+	historyDurationPrecision = time.Millisecond
+)
+
+var (
+	historyEntry  string
+	historyStatus string
+	historySince  string
+	historyUntil  string
+	historyLimit  int
+	historyFormat string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show invocations recorded in this workspace",
+	Long: `Show the tools and aliases run in this workspace, oldest first.
+
+Every run is recorded with the resolved program and arguments, working directory, start time,
+duration, exit code and origin (cli, mcp or api). Values that look like secrets are redacted
+before they are recorded.
+
+--since and --until accept an age such as "30m" or "7d", an RFC 3339 time or a date (2006-01-02).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		filter, err := historyFilterFromFlags(time.Now())
+		if err != nil {
+			return err
+		}
+
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+		records, err := workspace.History(filter)
+		if err != nil {
+			return err
+		}
+
+		switch historyFormat {
+		case formatText:
+			return writeHistoryText(cmd.OutOrStdout(), records)
+		case formatJSON:
+			return writeHistoryJSON(cmd.OutOrStdout(), records)
+		default:
+			return fmt.Errorf("unknown format %q: must be one of text, json", historyFormat)
+		}
+	},
+}
+
+func historyFilterFromFlags(now time.Time) (sidetable.HistoryFilter, error) {
+	filter := sidetable.HistoryFilter{Entry: historyEntry, Limit: historyLimit}
+
+	switch historyStatus {
+	case "":
+	case historyStatusOK, historyStatusFailed:
+		succeeded := historyStatus == historyStatusOK
+		filter.Succeeded = &succeeded
+	default:
+		return filter, fmt.Errorf("unknown status %q: must be one of ok, failed", historyStatus)
+	}
+
+	var err error
+	if filter.Since, err = parseTimeBound(historySince, now); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTimeBound(historyUntil, now); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseTimeBound parses an age relative to now, an RFC 3339 time or a local date.
+// An empty string means no bound.
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if age, err := parseAge(s); err == nil {
+		return now.Add(-age), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(historyDateLayout, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use an age such as 7d, an RFC 3339 time or a date", s)
+}
+
+func writeHistoryJSON(w io.Writer, records []sidetable.HistoryRecord) error {
+	if records == nil {
+		records = []sidetable.HistoryRecord{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+func writeHistoryText(w io.Writer, records []sidetable.HistoryRecord) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No invocations recorded")
+		return err
+	}
+
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Start
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Entry
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Exit
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Duration
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Origin
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Command
+	)
+
+	rows := make([][]string, 0, len(records)+1)
+	rows = append(rows, []string{"START", "ENTRY", "EXIT", "DURATION", "ORIGIN", "COMMAND"})
+	for _, record := range records {
+		exit := strconv.Itoa(record.ExitCode)
+		duration := record.Duration.Round(historyDurationPrecision).String()
+		switch {
+		case record.Error != "":
+			exit = "error"
+		case record.Exec:
+			exit, duration = "exec", "-"
+		}
+		rows = append(rows, []string{
+			record.Start.Local().Format(time.DateTime),
+			record.Entry,
+			exit,
+			duration,
+			string(record.Origin),
+			singleLine(strings.Join(append([]string{record.Program}, record.Args...), " ")),
+		})
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyEntry, "tool", "t", "", "only show invocations of this tool or alias")
+	historyCmd.Flags().StringVar(&historyStatus, "status", "", "only show invocations with this outcome (ok, failed)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only show invocations started at or after this time")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "only show invocations started at or before this time")
+	//nolint:mnd // default number of invocations shown
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "show at most this many of the most recent invocations (0 for all)")
+	historyCmd.Flags().StringVarP(
+		&historyFormat,
+		"format",
+		"f",
+		formatText,
+		"output format (text, json)",
+	)
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,81 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+)
+
+func TestHistoryAndAgainCommands(t *testing.T) {
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  hello:\n    run: echo\n")
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() {
+		historyStatus = ""
+		historyFormat = formatText
+	})
+
+	var out, errOut bytes.Buffer
+	historyCmd.SetOut(&out)
+	require.NoError(t, historyCmd.RunE(historyCmd, nil))
+	require.Equal(t, "No invocations recorded\n", out.String())
+
+	require.ErrorIs(t, againCmd.RunE(againCmd, nil), sidetable.ErrHistoryEmpty)
+
+	workspace, err := openWorkspace()
+	require.NoError(t, err)
+	require.NoError(t, workspace.Run(t.Context(), "hello", []string{"world"}, sidetable.InvokeOptions{
+		Stdout: &bytes.Buffer{},
+		Origin: sidetable.OriginCLI,
+	}))
+
+	out.Reset()
+	againCmd.SetOut(&out)
+	againCmd.SetErr(&errOut)
+	require.NoError(t, againCmd.RunE(againCmd, nil))
+	require.Equal(t, "world\n", out.String())
+	require.Equal(t, "sidetable hello world\n", errOut.String())
+
+	out.Reset()
+	require.NoError(t, historyCmd.RunE(historyCmd, nil))
+	require.Regexp(t, `START\s+ENTRY\s+EXIT\s+DURATION\s+ORIGIN\s+COMMAND`, out.String())
+	require.Regexp(t, `hello\s+0\s+\S+\s+cli\s+echo world`, out.String())
+
+	out.Reset()
+	historyStatus = historyStatusFailed
+	require.NoError(t, historyCmd.RunE(historyCmd, nil))
+	require.Equal(t, "No invocations recorded\n", out.String())
+
+	out.Reset()
+	historyStatus = ""
+	historyFormat = formatJSON
+	require.NoError(t, historyCmd.RunE(historyCmd, nil))
+	var records []sidetable.HistoryRecord
+	require.NoError(t, json.Unmarshal(out.Bytes(), &records))
+	require.Len(t, records, 2)
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	bound, err := parseTimeBound("2d", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-48*time.Hour), bound)
+
+	bound, err = parseTimeBound("2026-01-02T03:04:05Z", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), bound)
+
+	bound, err = parseTimeBound("2026-01-02", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local), bound)
+
+	_, err = parseTimeBound("yesterday", now)
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+)
+
+var importConflict string
+
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Extract tool directories from an archive written by export",
+	Long: `Extract tool directories from an archive written by "sidetable export" into the tool area.
+Use "-" to read the archive from standard input.
+
+--on-conflict decides what happens when a tool directory already exists:
+  skip       keep the existing directory (default)
+  overwrite  remove the existing directory first
+  rename     move the existing directory to <tool>.bak.<timestamp> first
+The directories of running services and of tools whose lock is held are always kept.
+
+Tools that are not configured are skipped. Entries with absolute paths, ".." elements
+or paths through symbolic links are rejected before anything is moved into the tool area.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		var in io.Reader = cmd.InOrStdin()
+		if args[0] != "-" {
+			f, openErr := os.Open(args[0])
+			if openErr != nil {
+				return openErr
+			}
+			defer f.Close()
+			in = f
+		}
+
+		result, err := workspace.Import(in, sidetable.ConflictStrategy(importConflict))
+		if result != nil {
+			writeImportResult(cmd.OutOrStdout(), cmd.ErrOrStderr(), result)
+		}
+		return err
+	},
+}
+
+func writeImportResult(out, errOut io.Writer, result *sidetable.ImportResult) {
+	if !result.ConfigMatches {
+		fmt.Fprintln(errOut, "Warning: the archive was exported with a different config")
+	}
+	for _, tool := range result.Tools {
+		switch tool.Action {
+		case sidetable.ImportActionSkipped:
+			fmt.Fprintf(out, "Skipped %s (%s)\n", tool.Name, tool.Reason)
+		case sidetable.ImportActionRenamed:
+			fmt.Fprintf(out, "Imported %s to %s (existing directory moved to %s)\n", tool.Name, tool.Path, tool.Backup)
+		case sidetable.ImportActionOverwritten:
+			fmt.Fprintf(out, "Imported %s to %s (existing directory replaced)\n", tool.Name, tool.Path)
+		default:
+			fmt.Fprintf(out, "Imported %s to %s\n", tool.Name, tool.Path)
+		}
+	}
+}
+
+func init() {
+	importCmd.Flags().StringVar(
+		&importConflict,
+		"on-conflict",
+		string(sidetable.ConflictSkip),
+		"what to do when a tool directory exists (skip, overwrite, rename)",
+	)
+	rootCmd.AddCommand(importCmd)
+}
@@ -1,46 +1,139 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/fileutil"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+// initBackupTimeFormat is used in the names of backups created by init --force.
+const initBackupTimeFormat = "20060102-150405"
+
+var (
+	initTemplate string
+	initLocal    bool
+	initFrom     string
+	initForce    bool
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize the sidetable configuration",
+	Long: `Create a sidetable configuration file from a starter template.
+
+By default the global config is created. With --local, a project-level ` + config.ProjectConfigFileName + `
+is created in the current directory instead; it is trusted and takes precedence over the global config for this project.
+
+Available templates: ` + strings.Join(config.Templates(), ", ") + `
+
+With --force, an existing config is moved to a timestamped backup before the new one is written.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, _ []string) error {
-		path, err := config.GetConfigPath()
+		path, err := initConfigPath()
 		if err != nil {
 			return err
 		}
 
-		if _, statErr := os.Stat(path); statErr == nil {
-			return fmt.Errorf("config already exists: %s", path)
-		} else if !os.IsNotExist(statErr) {
-			return statErr
+		data, err := initConfigSource()
+		if err != nil {
+			return err
+		}
+		if _, err = config.Parse(data, path, configLoadOptions()...); err != nil {
+			return errors.Join(fmt.Errorf("generated config is invalid: %s", path), err)
+		}
+
+		out := cmd.OutOrStdout()
+		backup := ""
+		if old, readErr := os.ReadFile(path); readErr == nil {
+			if !initForce {
+				return fmt.Errorf("config already exists: %s (use --force to replace it)", path)
+			}
+			// The old config is copied rather than moved, so it stays in place when writing the new one fails.
+			backup = path + ".bak." + time.Now().Format(initBackupTimeFormat)
+			if err = writeNewFile(backup, old); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(readErr) {
+			return readErr
 		}
 
 		dir := filepath.Dir(path)
 		err = os.MkdirAll(dir, 0o700)
-		if err != nil {
-			return err
+		if err == nil {
+			err = fileutil.WriteFileAtomic(path, data, 0o600)
 		}
-
-		err = os.WriteFile(path, config.DefaultConfigYAML, 0o600)
 		if err != nil {
+			if backup != "" {
+				_ = os.Remove(backup)
+			}
 			return err
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", path)
+		if backup != "" {
+			fmt.Fprintf(out, "Backed up %s to %s\n", path, backup)
+		}
+		if initLocal {
+			// A config the user just created needs no review before it is used.
+			if _, err = trust.Add(path, time.Now()); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "Created %s\n", path)
 		return nil
 	},
 }
 
+// writeNewFile writes data to path, failing when path already exists.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+func initConfigPath() (string, error) {
+	if !initLocal {
+		return config.GetConfigPath()
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return config.ProjectConfigPath(cwd), nil
+}
+
+func initConfigSource() ([]byte, error) {
+	if initFrom != "" {
+		return os.ReadFile(initFrom)
+	}
+	return config.Template(initTemplate)
+}
+
 func init() {
+	initCmd.Flags().StringVarP(&initTemplate, "template", "t", config.DefaultTemplate, "starter template to use")
+	initCmd.Flags().BoolVar(&initLocal, "local", false, "create a project-level config in the current directory")
+	initCmd.Flags().StringVar(&initFrom, "from", "", "copy the config from this file instead of a template")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "replace an existing config, keeping a timestamped backup")
+	initCmd.MarkFlagsMutuallyExclusive("template", "from")
+	_ = initCmd.RegisterFlagCompletionFunc(
+		"template",
+		cobra.FixedCompletions(config.Templates(), cobra.ShellCompDirectiveNoFileComp),
+	)
 	rootCmd.AddCommand(initCmd)
 }
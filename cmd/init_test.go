@@ -47,3 +47,99 @@ func TestInitCommandFailsWhenConfigExists(t *testing.T) {
 	require.NoError(t, readErr)
 	require.Equal(t, "directory: .sidetable\ntools: {x: {run: echo}}\n", string(data))
 }
+
+func resetInitFlags(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		initTemplate = config.DefaultTemplate
+		initLocal = false
+		initFrom = ""
+		initForce = false
+	})
+}
+
+func TestInitCommandUsesTemplate(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("SIDETABLE_CONFIG_DIR", base)
+	resetInitFlags(t)
+
+	initTemplate = "ghq"
+	initCmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, initCmd.RunE(initCmd, []string{}))
+
+	want, err := config.Template("ghq")
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(base, "config.yml"))
+	require.NoError(t, err)
+	require.Equal(t, want, data)
+
+	initTemplate = "nope"
+	require.ErrorIs(t, initCmd.RunE(initCmd, []string{}), config.ErrTemplateUnknown)
+}
+
+func TestInitCommandLocal(t *testing.T) {
+	t.Setenv("SIDETABLE_CONFIG_DIR", t.TempDir())
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	resetInitFlags(t)
+
+	initLocal = true
+	initCmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, initCmd.RunE(initCmd, []string{}))
+
+	_, err := os.Stat(filepath.Join(dir, config.ProjectConfigFileName))
+	require.NoError(t, err)
+
+	path, err := findConfigPath()
+	require.NoError(t, err)
+	require.Equal(t, config.ProjectConfigFileName, filepath.Base(path))
+}
+
+func TestInitCommandFromFile(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("SIDETABLE_CONFIG_DIR", base)
+	resetInitFlags(t)
+
+	src := filepath.Join(t.TempDir(), "shared.yml")
+	require.NoError(t, os.WriteFile(src, []byte("version: 1\ndirectory: .shared\ntools: {}\n"), 0o600))
+	initFrom = src
+	initCmd.SetOut(&bytes.Buffer{})
+	require.NoError(t, initCmd.RunE(initCmd, []string{}))
+
+	data, err := os.ReadFile(filepath.Join(base, "config.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "version: 1\ndirectory: .shared\ntools: {}\n", string(data))
+
+	require.NoError(t, os.WriteFile(src, []byte("directory: /abs\n"), 0o600))
+	initForce = true
+	err = initCmd.RunE(initCmd, []string{})
+	require.ErrorContains(t, err, "generated config is invalid")
+	require.ErrorContains(t, err, "directory must be relative")
+}
+
+func TestInitCommandForceBacksUpExistingConfig(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("SIDETABLE_CONFIG_DIR", base)
+	resetInitFlags(t)
+
+	path := filepath.Join(base, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	initForce = true
+	var buf bytes.Buffer
+	initCmd.SetOut(&buf)
+	require.NoError(t, initCmd.RunE(initCmd, []string{}))
+	require.Contains(t, buf.String(), "Backed up "+path+" to "+path+".bak.")
+
+	backups, err := filepath.Glob(path + ".bak.*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	old, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.Equal(t, "old", string(old))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, config.DefaultConfigYAML, data)
+}
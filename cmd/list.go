@@ -1,27 +1,63 @@
 package cmd
 
 import (
-	"os"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
 
+	"github.com/goccy/go-yaml"
 	"github.com/spf13/cobra"
 
 	"github.com/sushichan044/sidetable"
 	"github.com/sushichan044/sidetable/internal/spacing"
 )
 
+const (
+	listFormatTable    = "table"
+	listFormatJSON     = "json"
+	listFormatYAML     = "yaml"
+	listFormatTSV      = "tsv"
+	listFormatMarkdown = "markdown"
+)
+
+var (
+	listFormat   string
+	listTemplate string
+	listLong     bool
+	listKind     string
+	listTags     []string
+	listSearch   string
+)
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available tools and aliases",
 	Long: `List available tools and aliases defined in the sidetable configuration for the current project.
 
-The output shows entry name, kind, target, and description for each configured entry.`,
+The output shows entry name, kind, target, and description for each configured entry.
+With --long, the tags, run target, environment variable names, and instructions are included as well.
+
+Use --kind, --tag and --search to narrow the list. --tag may be repeated; entries with any of the given tags are listed.
+--search matches name, description and instructions, ignoring case.
+
+Output formats:
+  table     aligned columns (default)
+  json      JSON array of entries
+  yaml      YAML sequence of entries
+  tsv       tab-separated values with a header row
+  markdown  Markdown table
+
+Use --template to render each entry with a Go template instead, for example:
+  sidetable list --template '{{.Name}}{{"\t"}}{{.Run}}'`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, _ []string) error {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return err
+		if listKind != "" && listKind != string(sidetable.EntryKindTool) && listKind != string(sidetable.EntryKindAlias) {
+			return fmt.Errorf("unknown kind %q: must be one of tool, alias", listKind)
 		}
 
-		workspace, err := sidetable.Open(cwd)
+		workspace, err := openWorkspace()
 		if err != nil {
 			return err
 		}
@@ -31,36 +67,268 @@ The output shows entry name, kind, target, and description for each configured e
 			return catalogErr
 		}
 
-		formatter := spacing.NewFormatter(
-			spacing.Column(), // Entry name
+		filter := sidetable.EntryFilter{
+			Kind:   sidetable.EntryKind(listKind),
+			Tags:   listTags,
+			Search: listSearch,
+		}
+		matched := catalog.Filter(filter)
+		entries := make([]listedEntry, 0, len(matched))
+		for _, entry := range matched {
+			entries = append(entries, newListedEntry(entry))
+		}
+
+		out := cmd.OutOrStdout()
+		if listTemplate != "" {
+			return writeListTemplate(out, listTemplate, entries)
+		}
+
+		switch listFormat {
+		case listFormatTable:
+			return writeListTable(out, entries, listLong)
+		case listFormatJSON:
+			return writeListJSON(out, trimListedEntries(entries, listLong))
+		case listFormatYAML:
+			return writeListYAML(out, trimListedEntries(entries, listLong))
+		case listFormatTSV:
+			return writeListTSV(out, entries, listLong)
+		case listFormatMarkdown:
+			return writeListMarkdown(out, entries, listLong)
+		default:
+			return fmt.Errorf(
+				"unknown format %q: must be one of table, json, yaml, tsv, markdown",
+				listFormat,
+			)
+		}
+	},
+}
+
+// listedEntry is the machine-readable form of a catalog entry.
+// It is also the data passed to --template.
+type listedEntry struct {
+	Name         string   `json:"name"                   yaml:"name"`
+	Kind         string   `json:"kind"                   yaml:"kind"`
+	Group        string   `json:"group,omitempty"        yaml:"group,omitempty"`
+	Target       string   `json:"target,omitempty"       yaml:"target,omitempty"`
+	Description  string   `json:"description"            yaml:"description"`
+	Available    bool     `json:"available"              yaml:"available"`
+	Tags         []string `json:"tags,omitempty"         yaml:"tags,omitempty"`
+	Run          string   `json:"run,omitempty"          yaml:"run,omitempty"`
+	Env          []string `json:"env,omitempty"          yaml:"env,omitempty"`
+	Instructions string   `json:"instructions,omitempty" yaml:"instructions,omitempty"`
+}
+
+func newListedEntry(entry sidetable.Entry) listedEntry {
+	return listedEntry{
+		Name:         entry.Name,
+		Kind:         string(entry.Kind),
+		Group:        entry.Group,
+		Target:       entry.Target,
+		Description:  entry.Description,
+		Available:    entry.Available,
+		Tags:         entry.Tags,
+		Run:          entry.Run,
+		Env:          entry.EnvKeys,
+		Instructions: entry.Instructions,
+	}
+}
+
+// trimListedEntries drops the --long fields unless long is set.
+func trimListedEntries(entries []listedEntry, long bool) []listedEntry {
+	if long {
+		return entries
+	}
+	trimmed := make([]listedEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.Tags = nil
+		entry.Run = ""
+		entry.Env = nil
+		entry.Instructions = ""
+		trimmed = append(trimmed, entry)
+	}
+	return trimmed
+}
+
+func listHeader(long bool) []string {
+	header := []string{"NAME", "KIND", "TARGET", "DESCRIPTION"}
+	if long {
+		header = append(header, "TAGS", "RUN", "ENV", "INSTRUCTIONS")
+	}
+	return header
+}
+
+// listRow renders entry as single-line cells.
+func listRow(entry listedEntry, long bool) []string {
+	target := "-"
+	if entry.Kind == string(sidetable.EntryKindAlias) {
+		target = entry.Target
+	}
+	description := entry.Description
+	if !entry.Available {
+		description = strings.TrimSpace("(unavailable on this platform) " + description)
+	}
+
+	row := []string{entry.Name, entry.Kind, target, singleLine(description)}
+	if long {
+		row = append(
+			row,
+			joinOrDash(entry.Tags),
+			orDash(entry.Run),
+			joinOrDash(entry.Env),
+			singleLine(entry.Instructions),
+		)
+	}
+	return row
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func joinOrDash(values []string) string {
+	return orDash(strings.Join(values, ","))
+}
+
+// singleLine collapses runs of whitespace, including newlines, into single spaces.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeListTable(w io.Writer, entries []listedEntry, long bool) error {
+	options := []spacing.Element{
+		spacing.Column(), // Entry name
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(2),
+		spacing.Column(), // Kind
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Target
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Description
+	}
+	if long {
+		options = append(
+			options,
 			//nolint:mnd // fixed spacing value for readability
-			spacing.MinSpacing(2),
-			spacing.Column(), // Kind
+			spacing.MinSpacing(4),
+			spacing.Column(), // Tags
 			//nolint:mnd // fixed spacing value for readability
 			spacing.MinSpacing(4),
-			spacing.Column(), // Target
+			spacing.Column(), // Run
 			//nolint:mnd // fixed spacing value for readability
 			spacing.MinSpacing(4),
-			spacing.Column(), // Description
+			spacing.Column(), // Env
+			//nolint:mnd // fixed spacing value for readability
+			spacing.MinSpacing(4),
+			spacing.Column(), // Instructions
 		)
+	}
+	formatter := spacing.NewFormatter(options...)
+
+	rows := make([][]string, 0, len(entries)+1)
+	rows = append(rows, listHeader(long))
+	for _, entry := range entries {
+		rows = append(rows, listRow(entry, long))
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+
+	return formatter.Println(w)
+}
 
-		rows := make([][]string, 0, len(catalog.Entries)+1)
-		rows = append(rows, []string{"NAME", "KIND", "TARGET", "DESCRIPTION"})
-		for _, entry := range catalog.Entries {
-			target := "-"
-			if entry.Kind == sidetable.EntryKindAlias {
-				target = entry.Target
-			}
-			rows = append(rows, []string{entry.Name, string(entry.Kind), target, entry.Description})
+func writeListJSON(w io.Writer, entries []listedEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func writeListYAML(w io.Writer, entries []listedEntry) error {
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeListTSV(w io.Writer, entries []listedEntry, long bool) error {
+	if _, err := fmt.Fprintln(w, strings.Join(listHeader(long), "\t")); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		row := listRow(entry, long)
+		for i, cell := range row {
+			row[i] = strings.ReplaceAll(cell, "\t", " ")
 		}
-		if fmtErr := formatter.AddRows(rows...); fmtErr != nil {
-			return fmtErr
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		return formatter.Println(cmd.OutOrStdout())
-	},
+func writeListMarkdown(w io.Writer, entries []listedEntry, long bool) error {
+	header := listHeader(long)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+
+	lines := make([]string, 0, len(entries)+2) //nolint:mnd // header and separator rows
+	lines = append(lines, markdownRow(header), markdownRow(separator))
+	for _, entry := range entries {
+		lines = append(lines, markdownRow(listRow(entry, long)))
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		escaped = append(escaped, strings.ReplaceAll(cell, "|", `\|`))
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+func writeListTemplate(w io.Writer, text string, entries []listedEntry) error {
+	tmpl, err := template.New("list").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parse template: %w", err)
+	}
+	for _, entry := range entries {
+		if err = tmpl.Execute(w, entry); err != nil {
+			return fmt.Errorf("execute template for %q: %w", entry.Name, err)
+		}
+		if _, err = fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func init() {
+	listCmd.Flags().StringVarP(
+		&listFormat,
+		"format",
+		"f",
+		listFormatTable,
+		"output format (table, json, yaml, tsv, markdown)",
+	)
+	listCmd.Flags().StringVar(&listTemplate, "template", "", "render each entry with a Go template")
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "include run target, env keys, and instructions")
+	listCmd.Flags().StringVar(&listKind, "kind", "", "only list entries of this kind (tool, alias)")
+	listCmd.Flags().StringArrayVarP(&listTags, "tag", "t", nil, "only list entries with this tag (repeatable)")
+	listCmd.Flags().StringVarP(&listSearch, "search", "s", "", "only list entries matching this text")
+	listCmd.MarkFlagsMutuallyExclusive("format", "template")
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,109 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testListedEntries() []listedEntry {
+	return []listedEntry{
+		{
+			Name:         "ghq",
+			Kind:         "tool",
+			Description:  "Clone repositories",
+			Available:    true,
+			Tags:         []string{"git", "vcs"},
+			Run:          "ghq",
+			Env:          []string{"GHQ_ROOT"},
+			Instructions: "Use ghq get.\nThen cd.",
+		},
+		{
+			Name:        "gg",
+			Kind:        "alias",
+			Target:      "ghq",
+			Description: "Shortcut | for ghq",
+			Available:   true,
+			Run:         "ghq",
+			Env:         []string{"GHQ_ROOT"},
+		},
+	}
+}
+
+func TestWriteListTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListTable(&buf, testListedEntries(), false))
+	require.Equal(t, ""+
+		"NAME  KIND     TARGET    DESCRIPTION\n"+
+		"ghq   tool     -         Clone repositories\n"+
+		"gg    alias    ghq       Shortcut | for ghq\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeListTable(&buf, testListedEntries(), true))
+	require.Contains(t, buf.String(), "RUN    ENV         INSTRUCTIONS\n")
+	require.Contains(t, buf.String(), "git,vcs    ghq    GHQ_ROOT    Use ghq get. Then cd.\n")
+}
+
+func TestWriteListJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListJSON(&buf, trimListedEntries(testListedEntries(), false)))
+
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	require.Equal(t, "ghq", decoded[0]["name"])
+	require.NotContains(t, decoded[0], "run")
+	require.NotContains(t, decoded[0], "target")
+	require.Equal(t, "ghq", decoded[1]["target"])
+
+	buf.Reset()
+	require.NoError(t, writeListJSON(&buf, trimListedEntries(testListedEntries(), true)))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, "ghq", decoded[0]["run"])
+	require.Equal(t, []any{"GHQ_ROOT"}, decoded[0]["env"])
+	require.Equal(t, []any{"git", "vcs"}, decoded[0]["tags"])
+}
+
+func TestWriteListYAML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListYAML(&buf, trimListedEntries(testListedEntries()[:1], false)))
+	require.Equal(t, ""+
+		"- name: ghq\n"+
+		"  kind: tool\n"+
+		"  description: Clone repositories\n"+
+		"  available: true\n", buf.String())
+}
+
+func TestWriteListTSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListTSV(&buf, testListedEntries(), true))
+	require.Equal(t, ""+
+		"NAME\tKIND\tTARGET\tDESCRIPTION\tTAGS\tRUN\tENV\tINSTRUCTIONS\n"+
+		"ghq\ttool\t-\tClone repositories\tgit,vcs\tghq\tGHQ_ROOT\tUse ghq get. Then cd.\n"+
+		"gg\talias\tghq\tShortcut | for ghq\t-\tghq\tGHQ_ROOT\t\n", buf.String())
+}
+
+func TestWriteListMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListMarkdown(&buf, testListedEntries(), false))
+	require.Equal(t, ""+
+		"| NAME | KIND | TARGET | DESCRIPTION |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| ghq | tool | - | Clone repositories |\n"+
+		`| gg | alias | ghq | Shortcut \| for ghq |`+"\n", buf.String())
+}
+
+func TestWriteListTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeListTemplate(&buf, "{{.Name}}={{.Run}}", testListedEntries()))
+	require.Equal(t, "ghq=ghq\ngg=ghq\n", buf.String())
+
+	err := writeListTemplate(&buf, "{{.Name", testListedEntries())
+	require.ErrorContains(t, err, "parse template")
+
+	err = writeListTemplate(&buf, "{{.Missing}}", testListedEntries())
+	require.ErrorContains(t, err, `execute template for "ghq"`)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"bytes"
 	"context"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -14,17 +13,18 @@ import (
 	internalmcp "github.com/sushichan044/sidetable/internal/mcp"
 )
 
+var mcpTags []string
+
 var mcpCmd = &cobra.Command{
-	Use:          "mcp",
-	Short:        "Start a stdio MCP server exposing sidetable tools",
+	Use:   "mcp",
+	Short: "Start a stdio MCP server exposing sidetable tools",
+	Long: `Start a stdio MCP server exposing the tools available on this platform.
+Service tools are not exposed.
+
+Use --tag to expose only tools with at least one of the given tags.`,
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return err
-		}
-
-		workspace, err := sidetable.Open(cwd)
+		workspace, err := openWorkspace()
 		if err != nil {
 			return err
 		}
@@ -34,9 +34,11 @@ var mcpCmd = &cobra.Command{
 			return err
 		}
 
-		tools := make([]internalmcp.ToolDef, 0, len(catalog.Entries))
-		for _, e := range catalog.Entries {
-			if e.Kind != sidetable.EntryKindTool {
+		entries := catalog.Filter(sidetable.EntryFilter{Kind: sidetable.EntryKindTool, Tags: mcpTags})
+		tools := make([]internalmcp.ToolDef, 0, len(entries))
+		for _, e := range entries {
+			// Services run until they are stopped, so an MCP call would never return.
+			if !e.Available || e.Service {
 				continue
 			}
 			desc := e.Instructions
@@ -49,9 +51,13 @@ var mcpCmd = &cobra.Command{
 		executor := func(ctx context.Context, name string, args []string) (string, string, error) {
 			var stdoutBuf, stderrBuf bytes.Buffer
 			runErr := workspace.Run(ctx, name, args, sidetable.InvokeOptions{
-				Stdin:  strings.NewReader(""),
-				Stdout: &stdoutBuf,
-				Stderr: &stderrBuf,
+				Stdin:       strings.NewReader(""),
+				Stdout:      &stdoutBuf,
+				Stderr:      &stderrBuf,
+				Origin:      sidetable.OriginMCP,
+				Timeout:     runTimeout,
+				LockNoWait:  runNoWait,
+				LockTimeout: runLockTimeout,
 			})
 			return stdoutBuf.String(), stderrBuf.String(), runErr
 		}
@@ -62,5 +68,6 @@ var mcpCmd = &cobra.Command{
 }
 
 func init() {
+	mcpCmd.Flags().StringArrayVarP(&mcpTags, "tag", "t", nil, "only expose tools with this tag (repeatable)")
 	rootCmd.AddCommand(mcpCmd)
 }
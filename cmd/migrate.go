@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [path]",
+	Short: "Upgrade the sidetable configuration to the latest version",
+	Long: `Upgrade the sidetable configuration file to the latest config version.
+
+Comments and key order are preserved.
+When path is omitted, the project-level .sidetable.yml or the global config is migrated.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			var err error
+			path, err = findConfigPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		migrated, applied, err := config.MigrateSource(source)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if migrateDryRun {
+			_, err = out.Write(migrated)
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Fprintf(out, "%s is already at version %d\n", path, config.CurrentVersion)
+			return nil
+		}
+
+		if err = writeConfigFile(path, migrated); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "Migrated %s to version %d\n", path, config.CurrentVersion)
+		for _, m := range applied {
+			fmt.Fprintf(out, "- %d -> %d: %s\n", m.From, m.To, m.Description)
+		}
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "print the migrated config instead of writing it")
+	rootCmd.AddCommand(migrateCmd)
+}
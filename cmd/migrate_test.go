@@ -0,0 +1,95 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+func TestMigrateCommandRewritesConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	src := "# area\ndirectory: .sidetable\ntools: {}\n"
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+
+	var buf bytes.Buffer
+	migrateCmd.SetOut(&buf)
+	migrateCmd.SetErr(&buf)
+
+	require.NoError(t, migrateCmd.RunE(migrateCmd, []string{path}))
+	require.Contains(t, buf.String(), "Migrated "+path+" to version 1")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "version: 1\n"+src, string(data))
+
+	buf.Reset()
+	require.NoError(t, migrateCmd.RunE(migrateCmd, []string{path}))
+	require.Contains(t, buf.String(), "already at version 1")
+}
+
+func TestMigrateCommandKeepsTrustedProjectConfigTrusted(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	path := config.ProjectConfigPath(t.TempDir())
+	require.NoError(t, os.WriteFile(path, []byte("directory: .sidetable\ntools: {}\n"), 0o600))
+	_, err := trust.Add(path, time.Now())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	migrateCmd.SetOut(&buf)
+	require.NoError(t, migrateCmd.RunE(migrateCmd, []string{path}))
+
+	trusted, err := trust.IsTrusted(path)
+	require.NoError(t, err)
+	require.True(t, trusted)
+}
+
+func TestMigrateCommandDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	src := "directory: .sidetable\n"
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+
+	migrateDryRun = true
+	t.Cleanup(func() { migrateDryRun = false })
+
+	var buf bytes.Buffer
+	migrateCmd.SetOut(&buf)
+
+	require.NoError(t, migrateCmd.RunE(migrateCmd, []string{path}))
+	require.Equal(t, "version: 1\n"+src, buf.String())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, src, string(data))
+}
+
+func TestMigrateCommandKeepsSymlinkedConfig(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "dotfiles", "config.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	src := "directory: .sidetable\ntools: {}\n"
+	require.NoError(t, os.WriteFile(target, []byte(src), 0o600))
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.Symlink(target, path); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	var buf bytes.Buffer
+	migrateCmd.SetOut(&buf)
+	migrateCmd.SetErr(&buf)
+
+	require.NoError(t, migrateCmd.RunE(migrateCmd, []string{path}))
+
+	info, err := os.Lstat(path)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink, "migrate must not replace the link")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "version: ")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+const hoursPerDay = 24
+
+var (
+	pruneOlderThan string
+	pruneDryRun    bool
+	pruneYes       bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove orphaned and stale directories from the tool area",
+	Long: `Remove directories in the tool area that do not belong to any configured tool.
+
+With --older-than, directories of configured tools and backups kept by "sidetable import --on-conflict rename"
+that have not been modified within the given age are removed as well.
+The age accepts Go durations such as "72h" and whole days such as "30d".
+
+Directories of running services and of tools whose lock is held by a run are left alone.
+The directories to remove are listed and confirmation is requested unless --yes is given.
+Symbolic links are removed without touching their targets, and nothing outside the workspace is ever removed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		olderThan, err := parseAge(pruneOlderThan)
+		if err != nil {
+			return err
+		}
+
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		candidates, err := workspace.PruneCandidates(olderThan, time.Now())
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(candidates) == 0 {
+			fmt.Fprintln(out, "Nothing to prune")
+			return nil
+		}
+
+		if err = writePruneCandidates(out, candidates); err != nil {
+			return err
+		}
+		if pruneDryRun {
+			return nil
+		}
+		if !pruneYes && !confirm(cmd.InOrStdin(), out, fmt.Sprintf("Remove %d directories?", len(candidates))) {
+			fmt.Fprintln(out, "Aborted")
+			return nil
+		}
+
+		for _, candidate := range candidates {
+			err = workspace.RemoveAreaDir(candidate.Name)
+			if errors.Is(err, sidetable.ErrToolDirInUse) {
+				// A service or a locked run may have started since the candidates were listed.
+				fmt.Fprintf(out, "Skipped %s: %v\n", candidate.Path, err)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("remove %s: %w", candidate.Path, err)
+			}
+			fmt.Fprintf(out, "Removed %s\n", candidate.Path)
+		}
+		return nil
+	},
+}
+
+func writePruneCandidates(w io.Writer, candidates []sidetable.PruneCandidate) error {
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Path
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Reason
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Size
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Modified
+	)
+
+	rows := make([][]string, 0, len(candidates)+1)
+	rows = append(rows, []string{"PATH", "REASON", "SIZE", "MODIFIED"})
+	for _, candidate := range candidates {
+		reason := string(candidate.Reason)
+		if candidate.Symlink {
+			reason += " (symlink)"
+		}
+		rows = append(rows, []string{
+			candidate.Path,
+			reason,
+			formatBytes(candidate.Size),
+			candidate.ModTime.Local().Format(reportTimeLayout),
+		})
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+// confirm asks a yes/no question and reports whether the answer was yes.
+// Anything other than "y" or "yes", including end of input, counts as no.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseAge parses a Go duration or a whole number of days such as "30d".
+// An empty string means no age limit.
+func parseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * hoursPerDay * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+func init() {
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "also remove tool directories not modified within this age (e.g. 30d, 72h)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "list the directories that would be removed without removing them")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "remove without asking for confirmation")
+	pruneCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+	rootCmd.AddCommand(pruneCmd)
+}
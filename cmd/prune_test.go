@@ -0,0 +1,73 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneCommand(t *testing.T) {
+	writeTempConfig(t, "version: 1\ndirectory: .private\ntools:\n  ghq:\n    run: ghq\n")
+	root := t.TempDir()
+	t.Chdir(root)
+	orphan := filepath.Join(".private", "old")
+	require.NoError(t, os.MkdirAll(orphan, 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(".private", "ghq"), 0o755))
+
+	t.Cleanup(func() {
+		pruneDryRun = false
+		pruneYes = false
+		pruneCmd.SetIn(nil)
+	})
+
+	var out bytes.Buffer
+	pruneCmd.SetOut(&out)
+
+	pruneDryRun = true
+	require.NoError(t, pruneCmd.RunE(pruneCmd, nil))
+	require.Contains(t, out.String(), "orphaned")
+	require.NotContains(t, out.String(), "ghq")
+	require.DirExists(t, orphan)
+	pruneDryRun = false
+
+	out.Reset()
+	pruneCmd.SetIn(strings.NewReader("n\n"))
+	require.NoError(t, pruneCmd.RunE(pruneCmd, nil))
+	require.Contains(t, out.String(), "Remove 1 directories? [y/N] Aborted")
+	require.DirExists(t, orphan)
+
+	out.Reset()
+	pruneCmd.SetIn(strings.NewReader("y\n"))
+	require.NoError(t, pruneCmd.RunE(pruneCmd, nil))
+	require.Contains(t, out.String(), "Removed ")
+	require.NoDirExists(t, orphan)
+
+	out.Reset()
+	require.NoError(t, pruneCmd.RunE(pruneCmd, nil))
+	require.Equal(t, "Nothing to prune\n", out.String())
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := parseAge("30d")
+	require.NoError(t, err)
+	require.Equal(t, 30*24*time.Hour, age)
+
+	age, err = parseAge("90m")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, age)
+
+	age, err = parseAge("")
+	require.NoError(t, err)
+	require.Zero(t, age)
+
+	_, err = parseAge("xd")
+	require.Error(t, err)
+	_, err = parseAge("-1h")
+	require.Error(t, err)
+}
@@ -2,13 +2,16 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
 	"github.com/sushichan044/sidetable/internal/errutils"
 	"github.com/sushichan044/sidetable/version"
 )
@@ -23,65 +26,116 @@ Use "sidetable list" to inspect available entries.
 Use "sidetable init" to scaffold a config file.`,
 	SilenceUsage: true,
 	Version:      version.Get(),
+	// Flags before a tool name, as in "sidetable --timeout 1m ghq", are parsed by the root command
+	// while everything after it is passed to the tool.
+	TraverseChildren: true,
+	// Traversal skips cobra's own unknown command check, so the root command reports it itself.
+	Args:              cobra.ArbitraryArgs,
+	PersistentPreRunE: checkStrictConfig,
+	RunE:              helpOrUnknownCommand,
 }
 
+// runTimeout overrides the timeout of tools run by this process when positive.
+var runTimeout time.Duration
+
+// runExec replaces the sidetable process with the tool even when the tool does not set exec.
+var runExec bool
+
+var (
+	// runNoWait fails instead of waiting when the lock of a tool is held by another process.
+	runNoWait bool
+	// runLockTimeout limits how long to wait for the lock of a tool when positive.
+	runLockTimeout time.Duration
+)
+
+// runStrict fails commands when the config has unknown fields instead of only warning about them.
+var runStrict bool
+
 var injectedUserCommands []*cobra.Command
 
+const (
+	// exitCodeProgramNotFound follows the shell convention for commands that cannot be found.
+	exitCodeProgramNotFound = 127
+	// exitCodeTimeout follows timeout(1) from GNU coreutils.
+	exitCodeTimeout = 124
+	// exitCodeLocked is EX_TEMPFAIL from sysexits.h: the tool may be run again once the lock is released.
+	exitCodeLocked = 75
+)
+
 // Execute executes the root command and returns the exit code.
 func Execute() int {
-	if err := injectUserDefinedCommands(); err != nil {
-		fmt.Fprintln(os.Stderr, color.RedString("Error occurred while loading config:"))
+	workspace, err := injectUserDefinedCommands()
+	if err != nil {
+		stderr := rootCmd.ErrOrStderr()
+		fmt.Fprintln(stderr, color.RedString("Error occurred while loading config:"))
 
 		// If config loading fails, print error details and continue.
 		// This allows users to use built-in commands like "help" or "init" anytime.
 		errs, _ := errutils.UnwrapJoinError(err)
 		for _, e := range errs {
-			fmt.Fprintln(os.Stderr, color.RedString("- %v", e))
+			fmt.Fprintln(stderr, color.RedString("- %v", e))
+			if diag, ok := config.AsDiagnostic(e); ok && diag.Snippet != "" {
+				fmt.Fprintln(stderr, indentLines(diag.Snippet, "  "))
+			}
 		}
-		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(stderr)
+	}
+	for _, warning := range workspace.Warnings() {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), color.YellowString("Warning: %s", warning))
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	if err = rootCmd.Execute(); err != nil {
 		return determineExitCode(err)
 	}
 	return 0
 }
 
+func indentLines(s string, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
 func determineExitCode(err error) int {
 	if err == nil {
 		return 0
 	}
 
+	if _, timedOut := sidetable.AsTimeoutError(err); timedOut {
+		return exitCodeTimeout
+	}
+
+	if _, locked := sidetable.AsLockError(err); locked {
+		return exitCodeLocked
+	}
+
 	invErr, ok := sidetable.AsInvocationError(err)
 	if ok {
 		return invErr.Code
 	}
 
+	if _, notFound := sidetable.AsProgramNotFoundError(err); notFound {
+		return exitCodeProgramNotFound
+	}
+
 	return 1
 }
 
-func injectUserDefinedCommands() error {
+func injectUserDefinedCommands() (*sidetable.Workspace, error) {
 	clearInjectedUserCommands()
 
-	cwd, err := os.Getwd()
-	if err != nil {
-		return err
-	}
-
-	workspace, err := sidetable.Open(cwd)
+	workspace, err := openWorkspace()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	subCommands, err := buildWorkspaceCommands(workspace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rootCmd.AddCommand(subCommands...)
 	injectedUserCommands = subCommands
 
-	return nil
+	return workspace, nil
 }
 
 func clearInjectedUserCommands() {
@@ -92,6 +146,8 @@ func clearInjectedUserCommands() {
 	injectedUserCommands = nil
 }
 
+// buildWorkspaceCommands returns the top-level commands for the workspace entries.
+// Grouped entries are nested below a command per group.
 func buildWorkspaceCommands(workspace *sidetable.Workspace) ([]*cobra.Command, error) {
 	catalog, err := workspace.Catalog()
 	if err != nil {
@@ -99,20 +155,116 @@ func buildWorkspaceCommands(workspace *sidetable.Workspace) ([]*cobra.Command, e
 	}
 
 	cmds := make([]*cobra.Command, 0, len(catalog.Entries))
+	groupCmds := make(map[string]*cobra.Command, len(catalog.Groups))
+	addCommand := func(parent string, cmd *cobra.Command) {
+		if parent == "" {
+			cmds = append(cmds, cmd)
+			return
+		}
+		groupCmds[parent].AddCommand(cmd)
+	}
+
+	// Groups are sorted so that parents are created before their children.
+	for _, group := range catalog.Groups {
+		name := group.Words[len(group.Words)-1]
+		short := group.Description
+		if short == "" {
+			short = "Commands in the " + strings.Join(group.Words, " ") + " group"
+		}
+		groupCmd := &cobra.Command{
+			Use:          name,
+			Short:        short,
+			Hidden:       !group.Available,
+			SilenceUsage: true,
+			Args:         cobra.ArbitraryArgs,
+			RunE:         helpOrUnknownCommand,
+		}
+		groupCmds[group.Name] = groupCmd
+		addCommand(group.Parent, groupCmd)
+	}
+
 	for _, entry := range catalog.Entries {
 		name := entry.Name
 		description := entry.Description
 		subCmd := &cobra.Command{
-			Use:                name,
+			Use:                entry.Words[len(entry.Words)-1],
 			Short:              description,
+			Hidden:             !entry.Available,
 			DisableFlagParsing: true,
 			SilenceUsage:       true,
 			RunE: func(_ *cobra.Command, args []string) error {
-				return workspace.Run(context.Background(), name, args, sidetable.InvokeOptions{})
+				return workspace.Run(context.Background(), name, args, sidetable.InvokeOptions{
+					Origin:         sidetable.OriginCLI,
+					Timeout:        runTimeout,
+					ForwardSignals: true,
+					Exec:           cliExecMode(),
+					LockNoWait:     runNoWait,
+					LockTimeout:    runLockTimeout,
+				})
 			},
 		}
-		cmds = append(cmds, subCmd)
+		addCommand(entry.Group, subCmd)
 	}
 
 	return cmds, nil
 }
+
+// suggestionsMinimumDistance matches cobra's default for "Did you mean this?" suggestions.
+const suggestionsMinimumDistance = 2
+
+// helpOrUnknownCommand shows help for a command that only groups subcommands,
+// or reports the unknown subcommand it was called with.
+func helpOrUnknownCommand(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	msg := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+	if cmd.SuggestionsMinimumDistance <= 0 {
+		cmd.SuggestionsMinimumDistance = suggestionsMinimumDistance
+	}
+	if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		msg += "\n\nDid you mean this?\n\t" + strings.Join(suggestions, "\n\t")
+	}
+	return errors.New(msg)
+}
+
+// cliExecMode returns how tools run from the command line may replace the sidetable process.
+func cliExecMode() sidetable.ExecMode {
+	if runExec {
+		return sidetable.ExecAlways
+	}
+	return sidetable.ExecConfigured
+}
+
+func init() {
+	rootCmd.Flags().BoolVar(
+		&runExec,
+		"exec",
+		false,
+		"replace sidetable with the tool instead of running it as a child process (Unix only)",
+	)
+	rootCmd.Flags().BoolVar(
+		&runNoWait,
+		"no-wait",
+		false,
+		"fail instead of waiting when a tool is locked by another run",
+	)
+	rootCmd.Flags().DurationVar(
+		&runLockTimeout,
+		"lock-timeout",
+		0,
+		"give up waiting for the lock of a tool after this long (e.g. 30s)",
+	)
+	rootCmd.Flags().DurationVar(
+		&runTimeout,
+		"timeout",
+		0,
+		`stop tools that run longer than this (e.g. 30s, 5m), overriding their configured timeout`,
+	)
+	rootCmd.PersistentFlags().BoolVar(
+		&runStrict,
+		"strict",
+		false,
+		"fail when the config has unknown fields instead of warning (overrides "+lenientConfigEnv+")",
+	)
+}
@@ -73,6 +73,7 @@ func runExecuteWithTempConfig(t *testing.T, configYAML string, args ...string) (
 	configPath := filepath.Join(configDir, "config.yml")
 	require.NoError(t, os.WriteFile(configPath, []byte(configYAML), 0o600))
 	t.Setenv("SIDETABLE_CONFIG_DIR", configDir)
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -92,3 +93,76 @@ func runExecuteWithTempConfig(t *testing.T, configYAML string, args ...string) (
 
 	return exitCode, stderr.String()
 }
+
+func TestExecuteWarnsAboutUnknownConfigFields(t *testing.T) {
+	configYAML := `directory: .sidetable
+tools:
+  cmd_typo:
+    run: "true"
+    descripton: "typo"
+`
+
+	exitCode, stderr := runExecuteWithTempConfig(t, configYAML, "cmd_typo")
+
+	require.Equal(t, 0, exitCode)
+	require.Contains(t, stderr, "Warning: ")
+	require.Contains(t, stderr, `tools["cmd_typo"].descripton: unknown field "descripton" (did you mean "description"?)`)
+}
+
+func TestExecuteStrictRejectsUnknownConfigFields(t *testing.T) {
+	t.Cleanup(func() { runStrict = false })
+	configYAML := `directory: .sidetable
+tools:
+  cmd_typo:
+    run: "true"
+    descripton: "typo"
+`
+
+	exitCode, stderr := runExecuteWithTempConfig(t, configYAML, "--strict", "cmd_typo")
+
+	require.Equal(t, 1, exitCode)
+	require.Contains(t, stderr, "Error: ")
+	require.Contains(t, stderr, `tools["cmd_typo"].descripton: unknown field "descripton" (did you mean "description"?)`)
+}
+
+func TestExecuteDoesNotWarnAboutMissingConfigVersion(t *testing.T) {
+	configYAML := `directory: .sidetable
+tools: {}
+`
+
+	exitCode, stderr := runExecuteWithTempConfig(t, configYAML, "list")
+	require.Equal(t, 0, exitCode)
+	require.NotContains(t, stderr, "Warning:")
+}
+
+func TestExecuteRunsGroupedEntries(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("skipping test; sh not found: %v", err)
+	}
+
+	configYAML := `version: 1
+directory: .sidetable
+groups:
+  cmd_db:
+    description: "Database tools"
+  cmd_db.schema: {}
+tools:
+  cmd_db.migrate:
+    run: sh
+    args:
+      prepend: ["-c", "exit 7"]
+aliases:
+  cmd_db.schema.dump:
+    tool: cmd_db.migrate
+`
+
+	exitCode, _ := runExecuteWithTempConfig(t, configYAML, "cmd_db", "migrate")
+	require.Equal(t, 7, exitCode)
+
+	exitCode, _ = runExecuteWithTempConfig(t, configYAML, "cmd_db", "schema", "dump")
+	require.Equal(t, 7, exitCode)
+
+	exitCode, stderr := runExecuteWithTempConfig(t, configYAML, "cmd_db", "nope")
+	require.Equal(t, 1, exitCode)
+	require.Contains(t, stderr, `unknown command "nope" for "sidetable cmd_db"`)
+}
@@ -3,7 +3,9 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -27,3 +29,18 @@ func TestDetermineExitCode(t *testing.T) {
 		require.Equal(t, 1, determineExitCode(errors.New("unexpected")))
 	})
 }
+
+func TestDetermineExitCodeProgramNotFound(t *testing.T) {
+	err := &sidetable.ProgramNotFoundError{Program: "missing"}
+	require.Equal(t, 127, determineExitCode(err))
+}
+
+func TestDetermineExitCodeTimeout(t *testing.T) {
+	err := &sidetable.TimeoutError{Timeout: time.Second, Err: errors.New("signal: terminated")}
+	require.Equal(t, 124, determineExitCode(err))
+}
+
+func TestDetermineExitCodeLocked(t *testing.T) {
+	err := fmt.Errorf("run: %w", &sidetable.LockError{Tool: "ghq", Path: "/tmp/ghq/.sidetable.lock", PID: 42})
+	require.Equal(t, 75, determineExitCode(err))
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+// logsFollowInterval is how often "logs --follow" checks the log file for new output.
+const logsFollowInterval = 200 * time.Millisecond
+
+var (
+	logsLines  int
+	logsFollow bool
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start <service> [args...]",
+	Short: "Start a service tool in the background",
+	Long: `Start a service tool (a tool with service: true) or an alias of one in the background.
+
+The process is detached from the terminal. Its output is appended to .sidetable.log
+and its PID is recorded in .sidetable.pid, both in the tool directory.
+A log larger than 10 MiB is moved to .sidetable.log.1 first, replacing the previous one.
+Arguments after the service name are passed to the tool.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServiceNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+		status, err := workspace.StartService(args[0], args[1:])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Started %s (PID %d); logs: %s\n", status.Name, status.State.PID, status.LogFile)
+		return nil
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop <service>...",
+	Short: "Stop running service tools",
+	Long: `Stop running service tools with their stop_signal (SIGTERM by default).
+
+A service that is still running 5 seconds later is killed. On Windows, services are killed right away.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServiceNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, name := range args {
+			status, stopErr := workspace.StopService(commandContext(cmd), name)
+			if stopErr != nil {
+				errs = append(errs, stopErr)
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Stopped %s (PID %d)\n", status.Name, status.State.PID)
+		}
+		return errors.Join(errs...)
+	},
+}
+
+var restartCmd = &cobra.Command{
+	Use:   "restart <service> [args...]",
+	Short: "Restart a service tool",
+	Long: `Stop a service tool if it is running and start it again.
+
+Without arguments, the service is started again with the arguments it was last started with.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeServiceNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		var userArgs []string
+		if len(args) > 1 {
+			userArgs = args[1:]
+		}
+		status, err := workspace.RestartService(commandContext(cmd), args[0], userArgs)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Restarted %s (PID %d); logs: %s\n", status.Name, status.State.PID, status.LogFile)
+		return nil
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <service>",
+	Short: "Print the output of a service tool",
+	Long: `Print the log file of a service tool, which collects its output across starts.
+
+Use --lines to print only the last lines, and --follow to keep printing new output until interrupted.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeServiceNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if logsLines < 0 {
+			return fmt.Errorf("--lines must not be negative: %d", logsLines)
+		}
+
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+		status, err := workspace.ServiceStatus(args[0])
+		if err != nil {
+			return err
+		}
+
+		offset, err := writeLogTail(cmd.OutOrStdout(), status.LogFile, logsLines)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s has no logs yet; start it with \"sidetable start %s\"", status.Name, args[0])
+		}
+		if err != nil || !logsFollow {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt)
+		defer stop()
+		return followLog(ctx, cmd.OutOrStdout(), status.LogFile, offset)
+	},
+}
+
+// commandContext returns the context of cmd, which is nil when its RunE is called directly.
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+func completeServiceNames(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	workspace, err := openWorkspace()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	services, err := workspace.Services()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(services))
+	for _, service := range services {
+		names = append(names, service.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// writeLogTail writes the last lines of the file at path to w, or all of it when lines is zero,
+// and returns the size of the file that was read.
+func writeLogTail(w io.Writer, path string, lines int) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	out := data
+	if lines > 0 {
+		start := len(data)
+		// A trailing newline ends the last line rather than starting a new one.
+		end := len(bytes.TrimSuffix(data, []byte("\n")))
+		for range lines {
+			i := bytes.LastIndexByte(data[:end], '\n')
+			if i < 0 {
+				start = 0
+				break
+			}
+			start, end = i+1, i
+		}
+		out = data[start:]
+	}
+	_, err = w.Write(out)
+	return int64(len(data)), err
+}
+
+// followLog writes output appended to the file at path after offset to w until ctx is done.
+func followLog(ctx context.Context, w io.Writer, path string, offset int64) error {
+	ticker := time.NewTicker(logsFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err == nil && info.Size() < offset {
+			// The log was truncated or replaced; start over.
+			offset = 0
+		}
+		var n int64
+		if err == nil {
+			n, err = io.Copy(w, io.NewSectionReader(f, offset, info.Size()-offset))
+			offset += n
+		}
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type serviceReport struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	PID      int      `json:"pid,omitempty"`
+	Started  string   `json:"started,omitempty"`
+	Entry    string   `json:"entry,omitempty"`
+	UserArgs []string `json:"user_args,omitempty"`
+	LogFile  string   `json:"log_file"`
+}
+
+func serviceState(status sidetable.ServiceStatus) string {
+	switch {
+	case status.Running:
+		return "running"
+	case status.Stale:
+		return "stale"
+	default:
+		return "stopped"
+	}
+}
+
+func newServiceReports(services []sidetable.ServiceStatus) []serviceReport {
+	reports := make([]serviceReport, 0, len(services))
+	for _, service := range services {
+		report := serviceReport{Name: service.Name, Status: serviceState(service), LogFile: service.LogFile}
+		if service.State != nil {
+			report.PID = service.State.PID
+			report.Started = service.State.StartedAt.Format(time.RFC3339)
+			report.Entry = service.State.Entry
+			report.UserArgs = service.State.UserArgs
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func writeServicesJSON(w io.Writer, services []sidetable.ServiceStatus) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newServiceReports(services))
+}
+
+func writeServicesText(w io.Writer, services []sidetable.ServiceStatus) error {
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Service
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Status
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // PID
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Started
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Log
+	)
+
+	rows := make([][]string, 0, len(services)+1)
+	rows = append(rows, []string{"SERVICE", "STATUS", "PID", "STARTED", "LOG"})
+	for _, service := range services {
+		pid, started := "-", "-"
+		if service.State != nil && service.Running {
+			pid = strconv.Itoa(service.State.PID)
+			started = service.State.StartedAt.Local().Format(reportTimeLayout)
+		}
+		rows = append(rows, []string{service.Name, serviceState(service), pid, started, service.LogFile})
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+func init() {
+	// Flags after the service name belong to the tool.
+	startCmd.Flags().SetInterspersed(false)
+	restartCmd.Flags().SetInterspersed(false)
+
+	logsCmd.Flags().IntVarP(&logsLines, "lines", "n", 0, "only print the last N lines (0 prints everything)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep printing new output until interrupted")
+
+	rootCmd.AddCommand(startCmd, stopCmd, restartCmd, logsCmd)
+}
@@ -0,0 +1,63 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+)
+
+func TestWriteServicesText(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local)
+	services := []sidetable.ServiceStatus{
+		{
+			Name:    "docs",
+			Running: true,
+			State:   &sidetable.ServiceState{PID: 4242, StartedAt: started},
+			LogFile: "/w/.private/docs/.sidetable.log",
+		},
+		{
+			Name:    "queue",
+			Stale:   true,
+			State:   &sidetable.ServiceState{PID: 99, StartedAt: started},
+			LogFile: "/w/.private/queue/.sidetable.log",
+		},
+		{Name: "web", LogFile: "/w/.private/web/.sidetable.log"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeServicesText(&buf, services))
+	require.Equal(t, ""+
+		"SERVICE    STATUS     PID     STARTED             LOG\n"+
+		"docs       running    4242    2026-01-02 03:04    /w/.private/docs/.sidetable.log\n"+
+		"queue      stale      -       -                   /w/.private/queue/.sidetable.log\n"+
+		"web        stopped    -       -                   /w/.private/web/.sidetable.log\n", buf.String())
+}
+
+func TestWriteLogTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	content := "one\ntwo\nthree\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	for lines, want := range map[int]string{
+		0: content,
+		1: "three\n",
+		2: "two\nthree\n",
+		5: content,
+	} {
+		var buf bytes.Buffer
+		offset, err := writeLogTail(&buf, path, lines)
+		require.NoError(t, err)
+		require.Equal(t, want, buf.String(), "lines=%d", lines)
+		require.Equal(t, int64(len(content)), offset)
+	}
+
+	_, err := writeLogTail(&bytes.Buffer{}, filepath.Join(t.TempDir(), "missing.log"), 0)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
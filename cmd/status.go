@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/spacing"
+)
+
+var statusFormat string
+
+var statusCmd = &cobra.Command{
+	Use:   "status [service...]",
+	Short: "Show disk usage of the tool area and the state of services",
+	Long: `Show, for every configured tool, whether its directory exists, its size, file count and last modification time.
+
+Directories in the tool area that do not belong to any configured tool are listed as orphaned;
+use "sidetable prune" to remove them. Backups that "sidetable import --on-conflict rename" kept are listed as backup. Symbolic links are reported but never followed.
+
+Service tools are listed with whether they are running. With service names, only those services are shown.`,
+	ValidArgsFunction: completeServiceNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if statusFormat != formatText && statusFormat != formatJSON {
+			return fmt.Errorf("unknown format %q: must be one of text, json", statusFormat)
+		}
+
+		workspace, err := openWorkspace()
+		if err != nil {
+			return err
+		}
+
+		if len(args) > 0 {
+			services := make([]sidetable.ServiceStatus, 0, len(args))
+			for _, name := range args {
+				service, serviceErr := workspace.ServiceStatus(name)
+				if serviceErr != nil {
+					return serviceErr
+				}
+				services = append(services, service)
+			}
+			if statusFormat == formatJSON {
+				return writeServicesJSON(cmd.OutOrStdout(), services)
+			}
+			return writeServicesText(cmd.OutOrStdout(), services)
+		}
+
+		status, err := workspace.AreaStatus()
+		if err != nil {
+			return err
+		}
+		services, err := workspace.Services()
+		if err != nil {
+			return err
+		}
+
+		if statusFormat == formatJSON {
+			return writeStatusJSON(cmd.OutOrStdout(), status, services)
+		}
+		if err = writeStatusText(cmd.OutOrStdout(), status); err != nil {
+			return err
+		}
+		if len(services) == 0 {
+			return nil
+		}
+		if _, err = fmt.Fprintln(cmd.OutOrStdout()); err != nil {
+			return err
+		}
+		return writeServicesText(cmd.OutOrStdout(), services)
+	},
+}
+
+type statusReport struct {
+	Area    string      `json:"area"`
+	Exists  bool        `json:"exists"`
+	Tools   []statusDir `json:"tools"`
+	Orphans []statusDir `json:"orphans"`
+	Backups []statusDir `json:"backups"`
+	// Services is left out when no service tool is configured.
+	Services []serviceReport `json:"services,omitempty"`
+}
+
+type statusDir struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Exists   bool   `json:"exists"`
+	Symlink  bool   `json:"symlink,omitempty"`
+	Size     int64  `json:"size"`
+	Files    int    `json:"files"`
+	Modified string `json:"modified,omitempty"`
+}
+
+func newStatusDirs(dirs []sidetable.DirStatus) []statusDir {
+	out := make([]statusDir, 0, len(dirs))
+	for _, dir := range dirs {
+		modified := ""
+		if !dir.ModTime.IsZero() {
+			modified = dir.ModTime.Format(time.RFC3339)
+		}
+		out = append(out, statusDir{
+			Name:     dir.Name,
+			Path:     dir.Path,
+			Exists:   dir.Exists,
+			Symlink:  dir.Symlink,
+			Size:     dir.Size,
+			Files:    dir.Files,
+			Modified: modified,
+		})
+	}
+	return out
+}
+
+func writeStatusJSON(w io.Writer, status *sidetable.AreaStatus, services []sidetable.ServiceStatus) error {
+	report := statusReport{
+		Area:    status.Dir,
+		Exists:  status.Exists,
+		Tools:   newStatusDirs(status.Tools),
+		Orphans: newStatusDirs(status.Orphans),
+		Backups: newStatusDirs(status.Backups),
+	}
+	if len(services) > 0 {
+		report.Services = newServiceReports(services)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeStatusText(w io.Writer, status *sidetable.AreaStatus) error {
+	area := "Area: " + status.Dir
+	if !status.Exists {
+		area += " (not created)"
+	}
+	if _, err := fmt.Fprintln(w, area); err != nil {
+		return err
+	}
+	if len(status.Tools) == 0 && len(status.Orphans) == 0 && len(status.Backups) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Tool
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Status
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Size
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Files
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Modified
+	)
+
+	rows := make([][]string, 0, len(status.Tools)+len(status.Orphans)+len(status.Backups)+1)
+	rows = append(rows, []string{"TOOL", "STATUS", "SIZE", "FILES", "MODIFIED"})
+	for _, dir := range status.Tools {
+		rows = append(rows, statusRow(dir))
+	}
+	for _, dir := range status.Orphans {
+		rows = append(rows, statusRow(dir))
+	}
+	for _, dir := range status.Backups {
+		rows = append(rows, statusRow(dir))
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+func statusRow(dir sidetable.DirStatus) []string {
+	if !dir.Exists {
+		return []string{dir.Name, "missing", "-", "-", "-"}
+	}
+
+	state := "ok"
+	switch {
+	case dir.Backup:
+		state = "backup"
+	case !dir.Configured:
+		state = "orphaned"
+	}
+	if dir.Symlink {
+		state += " (symlink)"
+	}
+
+	return []string{
+		dir.Name,
+		state,
+		formatBytes(dir.Size),
+		strconv.Itoa(dir.Files),
+		dir.ModTime.Local().Format(reportTimeLayout),
+	}
+}
+
+// formatBytes formats n with binary unit prefixes, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	statusCmd.Flags().StringVarP(
+		&statusFormat,
+		"format",
+		"f",
+		formatText,
+		"output format (text, json)",
+	)
+	rootCmd.AddCommand(statusCmd)
+}
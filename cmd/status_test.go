@@ -0,0 +1,48 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+)
+
+func TestWriteStatusText(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local)
+	status := &sidetable.AreaStatus{
+		Dir:    "/w/.private",
+		Exists: true,
+		Tools: []sidetable.DirStatus{
+			{Name: "ghq", Configured: true, Exists: true, Size: 1536, Files: 3, ModTime: modTime},
+			{Name: "note", Configured: true},
+		},
+		Orphans: []sidetable.DirStatus{
+			{Name: "old", Exists: true, Size: 10, Files: 1, ModTime: modTime},
+		},
+		Backups: []sidetable.DirStatus{
+			{Name: "ghq.bak.20260101-120000", Exists: true, Backup: true, Size: 10, Files: 1, ModTime: modTime},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeStatusText(&buf, status))
+	require.Equal(t, ""+
+		"Area: /w/.private\n"+
+		"\n"+
+		"TOOL                       STATUS      SIZE       FILES    MODIFIED\n"+
+		"ghq                        ok          1.5 KiB    3        2026-01-02 03:04\n"+
+		"note                       missing     -          -        -\n"+
+		"old                        orphaned    10 B       1        2026-01-02 03:04\n"+
+		"ghq.bak.20260101-120000    backup      10 B       1        2026-01-02 03:04\n", buf.String())
+}
+
+func TestFormatBytes(t *testing.T) {
+	require.Equal(t, "0 B", formatBytes(0))
+	require.Equal(t, "1023 B", formatBytes(1023))
+	require.Equal(t, "1.0 KiB", formatBytes(1024))
+	require.Equal(t, "2.5 MiB", formatBytes(5*1024*1024/2))
+}
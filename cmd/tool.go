@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+var (
+	toolAddRun          string
+	toolAddPath         []string
+	toolAddEnv          []string
+	toolAddPrepend      []string
+	toolAddAppend       []string
+	toolAddDescription  string
+	toolAddInstructions string
+	toolAddTags         []string
+)
+
+var toolCmd = &cobra.Command{
+	Use:   "tool",
+	Short: "Add, remove or rename tools in the configuration",
+	Long: `Add, remove or rename tools in the sidetable configuration.
+
+The config file is edited in place: comments and key order are preserved.
+The result is validated before it is written.`,
+}
+
+var toolAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a tool",
+	Example: `  sidetable tool add ghq --run ghq --env 'GHQ_ROOT={{.ToolDir}}'
+  sidetable tool add note --run vim --append '{{.ToolDir}}/note.md'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		env, err := parseEnvAssignments(toolAddEnv)
+		if err != nil {
+			return err
+		}
+		tool := config.Tool{
+			Run:          toolAddRun,
+			Path:         toolAddPath,
+			Args:         config.Args{Prepend: toolAddPrepend, Append: toolAddAppend},
+			Env:          env,
+			Description:  toolAddDescription,
+			Instructions: toolAddInstructions,
+			Tags:         toolAddTags,
+		}
+
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.AddTool(source, args[0], tool)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Added tool %s to %s\n", args[0], path)
+		return nil
+	},
+}
+
+var toolRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a tool",
+	Long:    "Remove a tool. Aliases targeting the tool must be removed first.",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.RemoveTool(source, args[0])
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed tool %s from %s\n", args[0], path)
+		return nil
+	},
+}
+
+var toolRenameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Rename a tool",
+	Long: `Rename a tool. Aliases targeting the tool are updated to the new name.
+
+The directory of the tool in the tool area is moved along with it. The rename is refused
+while the tool runs as a service or its lock is held, and when the new name already has a directory.`,
+	Args: cobra.ExactArgs(2), //nolint:mnd // old and new name
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName, newName := args[0], args[1]
+		// The directory is checked before the config changes. A config that does not load,
+		// such as one with tools named after builtin commands, is checked once the rename has repaired it.
+		workspace, openErr := openWorkspace()
+		if openErr == nil {
+			if err := workspace.CheckMoveToolDir(oldName, newName); err != nil {
+				return fmt.Errorf("config was not changed: %w", err)
+			}
+		}
+
+		path, err := updateConfigFile(func(source []byte) ([]byte, error) {
+			return config.RenameTool(source, oldName, newName)
+		})
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Renamed tool %s to %s in %s\n", oldName, newName, path)
+
+		if openErr != nil {
+			if workspace, openErr = openWorkspace(); openErr != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: the directory of %s was not moved: %v\n", oldName, openErr)
+				return nil
+			}
+		}
+		moved, err := workspace.MoveToolDir(oldName, newName)
+		if err != nil {
+			return fmt.Errorf("move the directory of %s: %w", oldName, err)
+		}
+		if moved {
+			area := workspace.AreaDir()
+			fmt.Fprintf(out, "Moved %s to %s\n", filepath.Join(area, oldName), filepath.Join(area, newName))
+		}
+		return nil
+	},
+}
+
+// parseEnvAssignments parses KEY=VALUE pairs.
+func parseEnvAssignments(assignments []string) (map[string]string, error) {
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid env %q: must be KEY=VALUE", assignment)
+		}
+		env[key] = value
+	}
+	return env, nil
+}
+
+func init() {
+	flags := toolAddCmd.Flags()
+	flags.StringVar(&toolAddRun, "run", "", "program to execute")
+	flags.StringArrayVar(&toolAddPath, "path", nil, "directory searched for the program before PATH (repeatable)")
+	flags.StringArrayVar(&toolAddEnv, "env", nil, "environment variable as KEY=VALUE (repeatable)")
+	flags.StringArrayVar(&toolAddPrepend, "prepend", nil, "argument inserted before user arguments (repeatable)")
+	flags.StringArrayVar(&toolAddAppend, "append", nil, "argument inserted after user arguments (repeatable)")
+	flags.StringVar(&toolAddDescription, "description", "", "description shown in sidetable list")
+	flags.StringVar(&toolAddInstructions, "instructions", "", "instructions for AI helpers")
+	flags.StringArrayVar(&toolAddTags, "tag", nil, "tag (repeatable)")
+	_ = toolAddCmd.MarkFlagRequired("run")
+
+	toolCmd.AddCommand(toolAddCmd, toolRemoveCmd, toolRenameCmd)
+	rootCmd.AddCommand(toolCmd)
+}
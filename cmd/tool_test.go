@@ -0,0 +1,147 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/filelock"
+)
+
+func writeTempConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv("SIDETABLE_CONFIG_DIR", dir)
+	return path
+}
+
+func TestToolAndAliasCommandsEditConfig(t *testing.T) {
+	path := writeTempConfig(t, "version: 1\n# area\ndirectory: .sidetable\ntools: {}\n")
+
+	toolAddRun = "ghq"
+	toolAddEnv = []string{"GHQ_ROOT={{.ToolDir}}"}
+	aliasAddPrepend = []string{"get", "-u"}
+	t.Cleanup(func() {
+		toolAddRun = ""
+		toolAddEnv = nil
+		aliasAddPrepend = nil
+	})
+
+	var buf bytes.Buffer
+	toolAddCmd.SetOut(&buf)
+	aliasAddCmd.SetOut(&buf)
+	toolRenameCmd.SetOut(&buf)
+
+	require.NoError(t, toolAddCmd.RunE(toolAddCmd, []string{"ghq"}))
+	require.NoError(t, aliasAddCmd.RunE(aliasAddCmd, []string{"gg", "ghq"}))
+	require.NoError(t, toolRenameCmd.RunE(toolRenameCmd, []string{"ghq", "repo"}))
+	require.Contains(t, buf.String(), "Added tool ghq to "+path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `version: 1
+# area
+directory: .sidetable
+tools:
+  repo:
+    run: ghq
+    env:
+      GHQ_ROOT: "{{.ToolDir}}"
+aliases:
+  gg:
+    tool: repo
+    args:
+      prepend:
+        - get
+        - -u
+`, string(data))
+}
+
+func TestToolCommandsRejectInvalidResults(t *testing.T) {
+	src := "version: 1\ndirectory: .sidetable\ntools:\n  ghq:\n    run: ghq\naliases:\n  gg:\n    tool: ghq\n"
+	path := writeTempConfig(t, src)
+
+	err := toolRemoveCmd.RunE(toolRemoveCmd, []string{"ghq"})
+	require.ErrorContains(t, err, "config was not changed")
+	require.ErrorContains(t, err, "alias tool not found")
+
+	toolAddRun = "ls"
+	t.Cleanup(func() { toolAddRun = "" })
+	err = toolAddCmd.RunE(toolAddCmd, []string{"list"})
+	require.ErrorContains(t, err, `tool conflicts with builtin command; rename it with "sidetable tool rename"`)
+
+	err = aliasAddCmd.RunE(aliasAddCmd, []string{"x", "missing"})
+	require.ErrorContains(t, err, "alias tool not found")
+
+	data, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	require.Equal(t, src, string(data))
+}
+
+func TestToolRenameMovesToolDirectory(t *testing.T) {
+	src := "version: 1\ndirectory: .sidetable\ntools:\n  ghq:\n    run: ghq\n  note:\n    run: note\n"
+	path := writeTempConfig(t, src)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join(".sidetable", "ghq"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(".sidetable", "note"), 0o755))
+
+	var buf bytes.Buffer
+	toolRenameCmd.SetOut(&buf)
+
+	// A run holding the lock of the tool keeps its directory in place.
+	f, err := os.OpenFile(filepath.Join(".sidetable", "note", sidetable.ToolLockFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	acquired, err := filelock.TryLock(f, false)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	err = toolRenameCmd.RunE(toolRenameCmd, []string{"note", "memo"})
+	require.ErrorIs(t, err, sidetable.ErrToolDirInUse)
+	require.ErrorContains(t, err, "config was not changed")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, src, string(data))
+
+	require.NoError(t, toolRenameCmd.RunE(toolRenameCmd, []string{"ghq", "repo"}))
+	require.Contains(t, buf.String(), "Moved ")
+	require.NoDirExists(t, filepath.Join(".sidetable", "ghq"))
+	require.DirExists(t, filepath.Join(".sidetable", "repo"))
+}
+
+func TestParseEnvAssignments(t *testing.T) {
+	env, err := parseEnvAssignments([]string{"A=1", "B=x=y", "C="})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"A": "1", "B": "x=y", "C": ""}, env)
+
+	_, err = parseEnvAssignments([]string{"NOPE"})
+	require.ErrorContains(t, err, "KEY=VALUE")
+}
+
+func TestToolRenameRepairsBuiltinConflictsOneAtATime(t *testing.T) {
+	// Tools named before "start" and "stop" became builtin commands.
+	src := "version: 1\ndirectory: .sidetable\ntools:\n  start:\n    run: a\n  stop:\n    run: b\n"
+	path := writeTempConfig(t, src)
+
+	require.NoError(t, toolRenameCmd.RunE(toolRenameCmd, []string{"start", "start-server"}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "start-server:")
+
+	// Edits that add issues are still refused.
+	err = aliasAddCmd.RunE(aliasAddCmd, []string{"x", "missing"})
+	require.ErrorContains(t, err, "config was not changed")
+
+	require.NoError(t, toolRenameCmd.RunE(toolRenameCmd, []string{"stop", "stop-server"}))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = config.Parse(data, path)
+	require.NoError(t, err)
+}
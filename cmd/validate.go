@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+const (
+	validateFormatText   = "text"
+	validateFormatJSON   = "json"
+	validateFormatGitHub = "github"
+)
+
+var validateFormat string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Validate the sidetable configuration",
+	Long: `Validate the sidetable configuration and report every issue with its location.
+
+When path is omitted, the project-level .sidetable.yml or the global config is validated.
+Unknown fields are reported as issues unless SIDETABLE_LENIENT_CONFIG is set.
+
+Output formats:
+  text    human-readable diagnostics with source snippets (default)
+  json    machine-readable diagnostics
+  github  GitHub Actions workflow annotations`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			var err error
+			path, err = findConfigPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		_, loadErr := config.Load(path, validateLoadOptions()...)
+		diags := config.Diagnostics(loadErr)
+
+		out := cmd.OutOrStdout()
+		var err error
+		switch validateFormat {
+		case validateFormatText:
+			err = writeDiagnosticsText(out, path, diags)
+		case validateFormatJSON:
+			err = writeDiagnosticsJSON(out, path, diags)
+		case validateFormatGitHub:
+			err = writeDiagnosticsGitHub(out, path, diags)
+		default:
+			return fmt.Errorf("unknown format %q: must be one of text, json, github", validateFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(diags) > 0 {
+			return fmt.Errorf("config has %d issue(s): %s", len(diags), path)
+		}
+		return nil
+	},
+}
+
+func writeDiagnosticsText(w io.Writer, path string, diags []config.Diagnostic) error {
+	if len(diags) == 0 {
+		_, err := fmt.Fprintf(w, "%s: OK\n", path)
+		return err
+	}
+
+	for _, diag := range diags {
+		if _, err := fmt.Fprintln(w, diag.String()); err != nil {
+			return err
+		}
+		if diag.Snippet == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, indentLines(diag.Snippet, "  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDiagnosticsJSON(w io.Writer, path string, diags []config.Diagnostic) error {
+	if diags == nil {
+		diags = []config.Diagnostic{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		File        string              `json:"file"`
+		Valid       bool                `json:"valid"`
+		Diagnostics []config.Diagnostic `json:"diagnostics"`
+	}{
+		File:        path,
+		Valid:       len(diags) == 0,
+		Diagnostics: diags,
+	})
+}
+
+// writeDiagnosticsGitHub writes diagnostics as GitHub Actions workflow commands.
+// See https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-error-message.
+func writeDiagnosticsGitHub(w io.Writer, path string, diags []config.Diagnostic) error {
+	for _, diag := range diags {
+		file := diag.File
+		if file == "" {
+			file = path
+		}
+
+		props := []string{"file=" + escapeGitHubProperty(file)}
+		if diag.Line > 0 {
+			props = append(props,
+				"line="+strconv.Itoa(diag.Line),
+				"col="+strconv.Itoa(diag.Column),
+			)
+		}
+		props = append(props, "title="+escapeGitHubProperty("sidetable config"))
+
+		message := diag.Message
+		if diag.Path != "" {
+			message = diag.Path + ": " + message
+		}
+
+		if _, err := fmt.Fprintf(w, "::error %s::%s\n", strings.Join(props, ","), escapeGitHubData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(
+		&validateFormat,
+		"format",
+		"f",
+		validateFormatText,
+		"output format (text, json, github)",
+	)
+	rootCmd.AddCommand(validateCmd)
+}
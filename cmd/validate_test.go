@@ -0,0 +1,85 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const invalidValidateConfig = `directory: .sidetable
+tools:
+  a:
+    run: "bad run"
+`
+
+func runValidateCommand(t *testing.T, format string, configYAML string) (string, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(configYAML), 0o600))
+
+	orig := validateFormat
+	validateFormat = format
+	t.Cleanup(func() { validateFormat = orig })
+
+	var buf bytes.Buffer
+	validateCmd.SetOut(&buf)
+	validateCmd.SetErr(&buf)
+
+	err := validateCmd.RunE(validateCmd, []string{path})
+	return buf.String(), err
+}
+
+func TestValidateCommandText(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		out, err := runValidateCommand(t, validateFormatText, "directory: .sidetable\ntools: {}\n")
+		require.NoError(t, err)
+		require.Contains(t, out, ": OK")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		out, err := runValidateCommand(t, validateFormatText, invalidValidateConfig)
+		require.Error(t, err)
+		require.Contains(t, out, `config.yml:4:10: tools["a"].run: tool run must not contain spaces`)
+		require.Contains(t, out, `   4 |     run: "bad run"`)
+		require.Contains(t, out, "     |          ^")
+	})
+}
+
+func TestValidateCommandJSON(t *testing.T) {
+	out, err := runValidateCommand(t, validateFormatJSON, invalidValidateConfig)
+	require.Error(t, err)
+
+	var report struct {
+		Valid       bool `json:"valid"`
+		Diagnostics []struct {
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+			Path    string `json:"path"`
+			Message string `json:"message"`
+		} `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	require.False(t, report.Valid)
+	require.Len(t, report.Diagnostics, 1)
+	require.Equal(t, 4, report.Diagnostics[0].Line)
+	require.Equal(t, 10, report.Diagnostics[0].Column)
+	require.Equal(t, `tools["a"].run`, report.Diagnostics[0].Path)
+}
+
+func TestValidateCommandGitHub(t *testing.T) {
+	out, err := runValidateCommand(t, validateFormatGitHub, invalidValidateConfig)
+	require.Error(t, err)
+	require.Contains(t, out, "::error file=")
+	require.Contains(t, out, ",line=4,col=10,title=sidetable config::tools[\"a\"].run: tool run must not contain spaces\n")
+}
+
+func TestEscapeGitHubProperty(t *testing.T) {
+	require.Equal(t, "C%3A\\a%2Cb%25%0A", escapeGitHubProperty("C:\\a,b%\n"))
+	require.Equal(t, "a:b,c%25%0D%0A", escapeGitHubData("a:b,c%\r\n"))
+}
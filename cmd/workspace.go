@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+// lenientConfigEnv disables unknown field warnings when set to a true value.
+const lenientConfigEnv = "SIDETABLE_LENIENT_CONFIG"
+
+// openWorkspace opens the workspace rooted at the current directory.
+func openWorkspace() (*sidetable.Workspace, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []sidetable.Option
+	if lenientConfig() {
+		opts = append(opts, sidetable.WithLenientConfig())
+	}
+
+	return sidetable.Open(cwd, opts...)
+}
+
+// findConfigPath returns the config path openWorkspace would load.
+func findConfigPath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return sidetable.FindConfigPath(cwd)
+}
+
+// configLoadOptions returns the config.Load options matching openWorkspace.
+// Unknown fields are only warned about unless --strict is given.
+func configLoadOptions() []config.LoadOption {
+	switch {
+	case runStrict:
+		return []config.LoadOption{config.WithStrict()}
+	case lenientConfig():
+		return []config.LoadOption{config.WithLenient()}
+	}
+	return nil
+}
+
+// validateLoadOptions returns the config.Load options for commands that validate the config,
+// which report unknown fields as issues unless SIDETABLE_LENIENT_CONFIG is set.
+func validateLoadOptions() []config.LoadOption {
+	if lenientConfig() && !runStrict {
+		return []config.LoadOption{config.WithLenient()}
+	}
+	return []config.LoadOption{config.WithStrict()}
+}
+
+// checkStrictConfig fails the command when --strict is given and the config has unknown fields.
+// Other config problems have already been reported while loading the workspace.
+func checkStrictConfig(_ *cobra.Command, _ []string) error {
+	if !runStrict {
+		return nil
+	}
+	path, err := findConfigPath()
+	if err != nil {
+		return nil //nolint:nilerr // reported by Execute
+	}
+	if _, err = config.Load(path, configLoadOptions()...); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func lenientConfig() bool {
+	lenient, _ := strconv.ParseBool(os.Getenv(lenientConfigEnv))
+	return lenient
+}
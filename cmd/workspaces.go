@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/spacing"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+var (
+	workspacesFormat        string
+	workspacesForgetMissing bool
+)
+
+var workspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "List workspaces sidetable has been used in",
+	Long: `List the workspaces tools have been run in, most recently used first,
+with the size of their tool area.
+
+Workspaces are recorded in a registry under the XDG state directory
+($XDG_STATE_HOME/sidetable, or $SIDETABLE_STATE_DIR when set) whenever a tool runs.
+Use --forget-missing to remove workspaces whose directory no longer exists.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		out := cmd.OutOrStdout()
+		if workspacesForgetMissing {
+			forgotten, err := sidetable.ForgetMissingWorkspaces()
+			if err != nil {
+				return err
+			}
+			for _, root := range forgotten {
+				fmt.Fprintf(out, "Forgot %s\n", root)
+			}
+			return nil
+		}
+
+		workspaces, err := sidetable.KnownWorkspaces()
+		if err != nil {
+			return err
+		}
+
+		switch workspacesFormat {
+		case formatText:
+			return writeWorkspacesText(out, workspaces)
+		case formatJSON:
+			return writeWorkspacesJSON(out, workspaces)
+		default:
+			return fmt.Errorf("unknown format %q: must be one of text, json", workspacesFormat)
+		}
+	},
+}
+
+type listedWorkspace struct {
+	Root      string `json:"root"`
+	Exists    bool   `json:"exists"`
+	LastUsed  string `json:"last_used"`
+	Area      string `json:"area"`
+	AreaSize  int64  `json:"area_size"`
+	AreaFiles int    `json:"area_files"`
+}
+
+func writeWorkspacesJSON(w io.Writer, workspaces []sidetable.KnownWorkspace) error {
+	listed := make([]listedWorkspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		listed = append(listed, listedWorkspace{
+			Root:      workspace.Root,
+			Exists:    workspace.Exists,
+			LastUsed:  workspace.LastUsed.Format(time.RFC3339),
+			Area:      workspace.Area.Path,
+			AreaSize:  workspace.Area.Size,
+			AreaFiles: workspace.Area.Files,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(listed)
+}
+
+func writeWorkspacesText(w io.Writer, workspaces []sidetable.KnownWorkspace) error {
+	if len(workspaces) == 0 {
+		_, err := fmt.Fprintln(w, "No workspaces recorded yet")
+		return err
+	}
+
+	formatter := spacing.NewFormatter(
+		spacing.Column(), // Root
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Last used
+		//nolint:mnd // fixed spacing value for readability
+		spacing.MinSpacing(4),
+		spacing.Column(), // Area size
+	)
+
+	rows := make([][]string, 0, len(workspaces)+1)
+	rows = append(rows, []string{"ROOT", "LAST USED", "AREA SIZE"})
+	for _, workspace := range workspaces {
+		size := formatBytes(workspace.Area.Size)
+		switch {
+		case !workspace.Exists:
+			size = "missing"
+		case !workspace.Area.Exists:
+			size = "-"
+		}
+		rows = append(rows, []string{
+			workspace.Root,
+			workspace.LastUsed.Local().Format(reportTimeLayout),
+			size,
+		})
+	}
+	if err := formatter.AddRows(rows...); err != nil {
+		return err
+	}
+	return formatter.Println(w)
+}
+
+var workspacesTrustCmd = &cobra.Command{
+	Use:   "trust [dir]",
+	Short: "Allow the project-level config of a workspace to be used",
+	Long: `Trust the ` + config.ProjectConfigFileName + ` in dir (the current directory by default).
+
+A project-level config comes with the repository it is in, so sidetable ignores it, and keeps using
+the global config, until it is trusted. Review the tools it defines before trusting it.
+Trust covers the current contents of the file, so it must be trusted again after it changes.
+Trusted configs are recorded under the XDG state directory.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := projectConfigArg(args)
+		if err != nil {
+			return err
+		}
+		if _, err = os.Stat(path); err != nil {
+			return err
+		}
+		added, err := trust.Add(path, time.Now())
+		if err != nil {
+			return err
+		}
+		if !added {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is already trusted\n", path)
+			return nil
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Trusted %s\n", path)
+		return nil
+	},
+}
+
+var workspacesUntrustCmd = &cobra.Command{
+	Use:   "untrust [dir]",
+	Short: "Stop using the project-level config of a workspace",
+	Long:  `Revoke trust in the ` + config.ProjectConfigFileName + ` in dir (the current directory by default).`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := projectConfigArg(args)
+		if err != nil {
+			return err
+		}
+		removed, err := trust.Remove(path)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("%s is not trusted", path)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Untrusted %s\n", path)
+		return nil
+	},
+}
+
+// projectConfigArg returns the project-level config path of the directory in args, or of the current directory.
+func projectConfigArg(args []string) (string, error) {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return config.ProjectConfigPath(dir), nil
+}
+
+func init() {
+	workspacesCmd.Flags().StringVarP(
+		&workspacesFormat,
+		"format",
+		"f",
+		formatText,
+		"output format (text, json)",
+	)
+	workspacesCmd.Flags().BoolVar(
+		&workspacesForgetMissing,
+		"forget-missing",
+		false,
+		"remove workspaces whose directory no longer exists from the registry",
+	)
+	workspacesCmd.AddCommand(workspacesTrustCmd, workspacesUntrustCmd)
+	rootCmd.AddCommand(workspacesCmd)
+}
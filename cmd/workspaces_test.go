@@ -0,0 +1,70 @@
+//nolint:testpackage // Need package-level access to unexported helpers.
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/registry"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+func TestWorkspacesCommand(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	existing := t.TempDir()
+	now := time.Now()
+	require.NoError(t, registry.Record(existing, existing+"/.private", now))
+	require.NoError(t, registry.Record("/nonexistent/project", "/nonexistent/project/.private", now.Add(-time.Hour)))
+
+	t.Cleanup(func() {
+		workspacesFormat = formatText
+		workspacesForgetMissing = false
+	})
+
+	var out bytes.Buffer
+	workspacesCmd.SetOut(&out)
+	require.NoError(t, workspacesCmd.RunE(workspacesCmd, nil))
+	require.Contains(t, out.String(), "ROOT")
+	require.Contains(t, out.String(), existing)
+	require.Regexp(t, `/nonexistent/project\s+\S+ \S+\s+missing`, out.String())
+
+	out.Reset()
+	workspacesForgetMissing = true
+	require.NoError(t, workspacesCmd.RunE(workspacesCmd, nil))
+	require.Equal(t, "Forgot /nonexistent/project\n", out.String())
+
+	out.Reset()
+	workspacesForgetMissing = false
+	workspacesFormat = formatJSON
+	require.NoError(t, workspacesCmd.RunE(workspacesCmd, nil))
+	require.Contains(t, out.String(), `"root": "`+existing+`"`)
+	require.NotContains(t, out.String(), "nonexistent")
+}
+
+func TestWorkspacesTrustCommands(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	dir := t.TempDir()
+	path := config.ProjectConfigPath(dir)
+
+	var out bytes.Buffer
+	workspacesTrustCmd.SetOut(&out)
+	require.ErrorIs(t, workspacesTrustCmd.RunE(workspacesTrustCmd, []string{dir}), os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\n"), 0o600))
+	require.NoError(t, workspacesTrustCmd.RunE(workspacesTrustCmd, []string{dir}))
+	require.Equal(t, "Trusted "+path+"\n", out.String())
+	trusted, err := trust.IsTrusted(path)
+	require.NoError(t, err)
+	require.True(t, trusted)
+
+	out.Reset()
+	workspacesUntrustCmd.SetOut(&out)
+	require.NoError(t, workspacesUntrustCmd.RunE(workspacesUntrustCmd, []string{dir}))
+	require.Equal(t, "Untrusted "+path+"\n", out.String())
+	require.ErrorContains(t, workspacesUntrustCmd.RunE(workspacesUntrustCmd, []string{dir}), "is not trusted")
+}
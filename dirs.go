@@ -0,0 +1,55 @@
+package sidetable
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+// toolDirPerm is used when creating tool directories.
+const toolDirPerm = 0o755
+
+// AreaDir returns the tool area: the configured directory under the workspace root.
+func (w *Workspace) AreaDir() string {
+	if w == nil || w.config == nil {
+		return ""
+	}
+	return areaDir(w.rootDir, w.config)
+}
+
+// ToolDir returns the directory of a tool or alias, the value of {{.ToolDir}} in templates.
+// Aliases resolve to the directory of their target tool.
+// The directory is not created; use EnsureToolDir for that.
+func (w *Workspace) ToolDir(name string) (string, error) {
+	if w == nil || w.config == nil {
+		return "", errors.New("workspace is not initialized")
+	}
+
+	resolved, err := w.config.ResolveEntry(name)
+	if err != nil {
+		return "", err
+	}
+	return toolDir(w.rootDir, w.config, resolved.ToolName), nil
+}
+
+// EnsureToolDir is like ToolDir but creates the directory when it does not exist.
+func (w *Workspace) EnsureToolDir(name string) (string, error) {
+	dir, err := w.ToolDir(name)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, toolDirPerm); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+func areaDir(root string, cfg *config.Config) string {
+	return filepath.Join(root, cfg.Directory)
+}
+
+func toolDir(root string, cfg *config.Config, toolName string) string {
+	return filepath.Join(areaDir(root, cfg), toolName)
+}
@@ -3,9 +3,58 @@ package sidetable
 import (
 	"fmt"
 	"maps"
+	"runtime"
+	"sort"
 	"strings"
 )
 
+// EnvChangeKind describes how an environment variable differs from the parent environment.
+type EnvChangeKind string
+
+const (
+	EnvChangeAdded    EnvChangeKind = "added"
+	EnvChangeModified EnvChangeKind = "modified"
+	EnvChangeRemoved  EnvChangeKind = "removed"
+)
+
+// EnvChange is a single environment variable difference.
+type EnvChange struct {
+	Key      string
+	Kind     EnvChangeKind
+	Value    string
+	Previous string
+}
+
+// EnvDiff returns the changes from parent to env, sorted by key.
+//
+//	changes := EnvDiff([]string{"A=1"}, []string{"A=2", "B=3"})
+//	// [{A modified 2 1} {B added 3 }]
+func EnvDiff(parent []string, env []string) []EnvChange {
+	before := envMapFromSlice(parent)
+	after := envMapFromSlice(env)
+
+	changes := make([]EnvChange, 0)
+	for key, value := range after {
+		previous, existed := before[key]
+		switch {
+		case !existed:
+			changes = append(changes, EnvChange{Key: key, Kind: EnvChangeAdded, Value: value})
+		case previous != value:
+			changes = append(changes, EnvChange{Key: key, Kind: EnvChangeModified, Value: value, Previous: previous})
+		}
+	}
+	for key, previous := range before {
+		if _, exists := after[key]; !exists {
+			changes = append(changes, EnvChange{Key: key, Kind: EnvChangeRemoved, Previous: previous})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
 // envMapFromSlice converts environment variables from slice format.
 //
 //	envMap := envMapFromSlice([]string{"KEY=value", "FOO=bar"})
@@ -21,6 +70,28 @@ func envMapFromSlice(env []string) map[string]string {
 	return result
 }
 
+// envLookup returns the value of key in env. As with os.Getenv, names are case-insensitive on Windows,
+// where PATH is usually spelled "Path". The last matching entry wins.
+//
+//	value, ok := envLookup([]string{"Path=C:\\bin"}, "PATH") // `C:\bin`, true on Windows
+func envLookup(env []string, key string) (string, bool) {
+	value, found := "", false
+	for _, entry := range env {
+		name, v, ok := strings.Cut(entry, "=")
+		if ok && envNameEqual(name, key) {
+			value, found = v, true
+		}
+	}
+	return value, found
+}
+
+func envNameEqual(a string, b string) bool {
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
 // envSliceFromMap converts environment variables from map format.
 //
 //	envSlice := envSliceFromMap(map[string]string{"KEY": "value", "FOO": "bar"})
@@ -3,8 +3,19 @@ package sidetable
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"time"
+)
+
+const (
+	// killGracePeriod is how long a cancelled or timed out process may take to exit before it is killed.
+	killGracePeriod = 5 * time.Second
+	// exitCodeSignalBase is added to the signal number of a tool killed by a signal, as shells do.
+	exitCodeSignalBase = 128
 )
 
 // execute executes an already resolved invocation.
@@ -21,8 +32,27 @@ func (w *Workspace) execute(ctx context.Context, inv Invocation, opts InvokeOpti
 		ctx = context.Background()
 	}
 
+	path := inv.Path
+	if path == "" {
+		path = inv.Program
+	}
+
+	timeout := invocationTimeout(inv, opts)
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// #nosec G204 -- command/args are from user-owned config; explicit delegation is intended.
-	cmd := exec.CommandContext(ctx, inv.Program, inv.Args...)
+	cmd := exec.CommandContext(runCtx, path, inv.Args...)
+	cmd.Args[0] = inv.Program
+	// On cancellation the process is asked to stop first and killed after the grace period.
+	cmd.Cancel = func() error {
+		return interruptProcess(cmd.Process)
+	}
+	cmd.WaitDelay = killGracePeriod
 	cmd.Env = inv.Env
 	if opts.Stdin != nil {
 		cmd.Stdin = opts.Stdin
@@ -40,11 +70,110 @@ func (w *Workspace) execute(ctx context.Context, inv Invocation, opts InvokeOpti
 		cmd.Stderr = os.Stderr
 	}
 
-	if err := cmd.Run(); err != nil {
+	restoreTerminal := configureProcess(cmd)
+
+	var signals chan os.Signal
+	if opts.ForwardSignals {
+		// Signals are caught before the tool starts, so that none of them terminates sidetable instead.
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, forwardedSignals...)
+		defer signal.Stop(signals)
+	}
+
+	if err := cmd.Start(); err != nil {
+		restoreTerminal()
+		return err
+	}
+	if signals != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go forwardSignals(cmd.Process, signals, done)
+	}
+
+	stopWatching := watchStops(cmd)
+	err := cmd.Wait()
+	stopWatching()
+	restoreTerminal()
+	if runCtx.Err() != nil {
+		// The tool was asked to stop; do not leave its children behind.
+		killProcessGroup(cmd.Process)
+	}
+	if err != nil {
+		if timeout > 0 && errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return &TimeoutError{Timeout: timeout, Err: err}
+		}
 		if exitErr := new(exec.ExitError); errors.As(err, &exitErr) {
-			return &InvocationError{Code: exitErr.ExitCode(), Err: err}
+			if sig, signaled := exitSignal(exitErr.ProcessState); signaled {
+				return &InvocationError{Code: exitCodeSignalBase + int(sig), Signal: sig, Attempts: 1, Err: err}
+			}
+			return &InvocationError{Code: exitErr.ExitCode(), Attempts: 1, Err: err}
 		}
 		return err
 	}
 	return nil
 }
+
+// forwardSignals passes the signals received by sidetable on to the tool until done is closed.
+func forwardSignals(p *os.Process, signals <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case sig := <-signals:
+			_ = signalProcess(p, sig)
+		case <-done:
+			return
+		}
+	}
+}
+
+// invocationTimeout returns the timeout applied to inv: the override from opts, or the configured one.
+func invocationTimeout(inv Invocation, opts InvokeOptions) time.Duration {
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	return inv.Timeout
+}
+
+// canReplaceProcess reports whether inv may replace the current process according to opts.
+func canReplaceProcess(inv Invocation, opts InvokeOptions) bool {
+	if !execSupported {
+		return false
+	}
+	switch opts.Exec {
+	case ExecAlways:
+	case ExecConfigured:
+		if !inv.Exec {
+			return false
+		}
+	default:
+		return false
+	}
+
+	// Nothing is left to enforce a timeout, retry or copy output once the process is replaced.
+	if invocationTimeout(inv, opts) > 0 || inv.Retry.Enabled() {
+		return false
+	}
+	return (opts.Stdin == nil || opts.Stdin == os.Stdin) &&
+		(opts.Stdout == nil || opts.Stdout == os.Stdout) &&
+		(opts.Stderr == nil || opts.Stderr == os.Stderr)
+}
+
+// replaceProcess replaces the current process with inv. It only returns when that fails.
+func replaceProcess(inv Invocation) error {
+	path := inv.Path
+	if path == "" {
+		path = inv.Program
+	}
+	argv := append([]string{inv.Program}, inv.Args...)
+	if err := execProcess(path, argv, inv.Env); err != nil {
+		return fmt.Errorf("failed to replace the process with %s: %w", path, err)
+	}
+	return nil
+}
+
+// stderrOf returns the writer receiving the standard error of tools run with opts.
+func stderrOf(opts InvokeOptions) io.Writer {
+	if opts.Stderr != nil {
+		return opts.Stderr
+	}
+	return os.Stderr
+}
@@ -0,0 +1,80 @@
+package sidetable_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+// stoppedToolHelperEnv makes TestWorkspaceRunContinuesStoppedForegroundTool run the tool inside a terminal.
+const stoppedToolHelperEnv = "SIDETABLE_TEST_STOPPED_TOOL_HELPER"
+
+// openPTY opens a pseudo terminal and returns its controlling and terminal sides.
+func openPTY(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo terminals are not available: %v", err)
+	}
+	t.Cleanup(func() { ptmx.Close() })
+	require.NoError(t, unix.IoctlSetPointerInt(int(ptmx.Fd()), unix.TIOCSPTLCK, 0))
+	n, err := unix.IoctlGetInt(int(ptmx.Fd()), unix.TIOCGPTN)
+	require.NoError(t, err)
+	pts, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	require.NoError(t, err)
+	return ptmx, pts
+}
+
+func TestWorkspaceRunContinuesStoppedForegroundTool(t *testing.T) {
+	if os.Getenv(stoppedToolHelperEnv) == "1" {
+		// Running in a session of its own with the terminal as stdin, sidetable hands the terminal to the tool.
+		ws := setupTestWorkspace(t, map[string]config.Tool{
+			"stopper": shellTool(`kill -STOP $$; echo resumed`),
+		}, nil)
+		require.NoError(t, ws.Run(context.Background(), "stopper", nil, sidetable.InvokeOptions{}))
+		return
+	}
+
+	ptmx, pts := openPTY(t)
+	// #nosec G204 -- re-runs this test binary.
+	helper := exec.Command(os.Args[0], "-test.run=^TestWorkspaceRunContinuesStoppedForegroundTool$", "-test.v")
+	helper.Env = append(os.Environ(), stoppedToolHelperEnv+"=1")
+	helper.Stdin, helper.Stdout, helper.Stderr = pts, pts, pts
+	helper.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	require.NoError(t, helper.Start())
+	pts.Close()
+
+	var out bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		// Reading fails with EIO once the helper has exited and closed the terminal.
+		_, _ = io.Copy(&out, ptmx)
+	}()
+
+	waited := make(chan error, 1)
+	go func() { waited <- helper.Wait() }()
+	select {
+	case err := <-waited:
+		<-copied
+		require.NoError(t, err, out.String())
+	case <-time.After(10 * time.Second):
+		_ = helper.Process.Kill()
+		<-waited
+		t.Fatal("sidetable kept waiting for the stopped tool")
+	}
+	require.Contains(t, out.String(), "resumed")
+}
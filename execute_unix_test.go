@@ -0,0 +1,115 @@
+//go:build !windows
+
+package sidetable_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func shellTool(script string) config.Tool {
+	return config.Tool{Run: "sh", Args: config.Args{Prepend: []string{"-c", script}}}
+}
+
+func TestWorkspaceRunReportsSignalExitCode(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"suicide": shellTool("kill -TERM $$"),
+	}, nil)
+
+	err := ws.Run(context.Background(), "suicide", nil, sidetable.InvokeOptions{})
+	invErr, ok := sidetable.AsInvocationError(err)
+	require.True(t, ok, "expected invocation error, got %v", err)
+	require.Equal(t, 128+int(syscall.SIGTERM), invErr.Code)
+	require.Equal(t, syscall.SIGTERM, invErr.Signal)
+}
+
+func TestWorkspaceRunTimeoutStopsProcessGroup(t *testing.T) {
+	// The trailing command keeps the shell from replacing itself with sleep,
+	// so sleep is a grandchild holding the output pipe open.
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"slow": shellTool("sleep 10; :"),
+	}, nil)
+
+	var stdout bytes.Buffer
+	start := time.Now()
+	err := ws.Run(context.Background(), "slow", nil, sidetable.InvokeOptions{
+		Stdout:  &stdout,
+		Timeout: 100 * time.Millisecond,
+	})
+	_, ok := sidetable.AsTimeoutError(err)
+	require.True(t, ok, "expected timeout error, got %v", err)
+	require.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestWorkspaceRunForwardsSignals(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"trap": shellTool(`trap "exit 3" TERM; echo ready; while :; do sleep 0.05; done`),
+	}, nil)
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	t.Cleanup(func() { stdoutReader.Close() })
+	go func() {
+		scanner := bufio.NewScanner(stdoutReader)
+		if scanner.Scan() {
+			_ = syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		}
+		_, _ = io.Copy(io.Discard, stdoutReader)
+	}()
+
+	err := ws.Run(context.Background(), "trap", nil, sidetable.InvokeOptions{
+		Stdin:          bytes.NewReader(nil),
+		Stdout:         stdoutWriter,
+		Timeout:        10 * time.Second,
+		ForwardSignals: true,
+	})
+	stdoutWriter.Close()
+	invErr, ok := sidetable.AsInvocationError(err)
+	require.True(t, ok, "expected invocation error, got %v", err)
+	require.Equal(t, 3, invErr.Code)
+	require.Nil(t, invErr.Signal)
+}
+
+func TestWorkspaceRunReplacesProcess(t *testing.T) {
+	if os.Getenv("SIDETABLE_TEST_EXEC_HELPER") == "1" {
+		root := os.Getenv("SIDETABLE_TEST_EXEC_ROOT")
+		ws, err := sidetable.Open(root, sidetable.WithConfigPath(config.ProjectConfigPath(root)))
+		require.NoError(t, err)
+		fmt.Printf("pid %d\n", os.Getpid())
+		err = ws.Run(context.Background(), "pid", nil, sidetable.InvokeOptions{Exec: sidetable.ExecConfigured})
+		t.Fatalf("Run returned instead of replacing the process: %v", err)
+	}
+
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"pid": {Run: "sh", Args: config.Args{Prepend: []string{"-c", "echo pid $$"}}, Exec: true},
+	}, nil)
+
+	// #nosec G204 -- re-runs the test binary itself.
+	cmd := exec.Command(os.Args[0], "-test.run=^TestWorkspaceRunReplacesProcess$")
+	cmd.Env = append(os.Environ(), "SIDETABLE_TEST_EXEC_HELPER=1", "SIDETABLE_TEST_EXEC_ROOT="+ws.Root())
+	cmd.Dir = ws.Root()
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	var pids []string
+	for line := range strings.Lines(string(out)) {
+		if pid, ok := strings.CutPrefix(strings.TrimSpace(line), "pid "); ok {
+			pids = append(pids, pid)
+		}
+	}
+	require.Len(t, pids, 2, string(out))
+	require.Equal(t, pids[0], pids[1], "the tool should run in the process of sidetable")
+}
@@ -0,0 +1,91 @@
+package sidetable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sushichan044/sidetable/internal/gitrepo"
+)
+
+// gitExcludeComment precedes the patterns sidetable adds to info/exclude.
+const gitExcludeComment = "added by sidetable"
+
+// GitStatus reports how the git repository enclosing the workspace treats the tool area.
+type GitStatus struct {
+	// Repository is false when the workspace root is not inside a git repository.
+	// The other fields are only set when it is true.
+	Repository bool
+	// WorkTree is the top-level directory of the enclosing working tree.
+	// Inside a submodule or linked worktree, it is that of the submodule or worktree.
+	WorkTree string
+	// ExcludeFile is the info/exclude file shared by all worktrees of the repository.
+	ExcludeFile string
+	// Pattern is the exclude pattern that matches the tool area.
+	Pattern string
+	// Excluded is true when Pattern is listed in ExcludeFile.
+	Excluded bool
+	// Ignored is true when git ignores the tool area by any rule.
+	Ignored bool
+	// Tracked lists files in the tool area that are tracked anyway, relative to WorkTree.
+	Tracked []string
+}
+
+// EnsureGitExclude lists the tool area in info/exclude of the git repository enclosing the workspace root.
+// It reports whether the exclude file was changed, and does nothing outside a git repository.
+//
+// Run calls it before executing a tool when the config sets git_exclude.
+func (w *Workspace) EnsureGitExclude() (bool, error) {
+	if w == nil || w.config == nil {
+		return false, errors.New("workspace is not initialized")
+	}
+
+	repo, err := gitrepo.Find(w.rootDir)
+	if errors.Is(err, gitrepo.ErrNotRepository) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	pattern, err := repo.Pattern(w.AreaDir())
+	if err != nil {
+		return false, err
+	}
+	return repo.Exclude(pattern, gitExcludeComment)
+}
+
+// GitStatus inspects how git treats the tool area. Checking ignore rules and the index requires the git binary.
+func (w *Workspace) GitStatus(ctx context.Context) (*GitStatus, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+
+	repo, err := gitrepo.Find(w.rootDir)
+	if errors.Is(err, gitrepo.ErrNotRepository) {
+		return &GitStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := &GitStatus{
+		Repository:  true,
+		WorkTree:    repo.WorkTree,
+		ExcludeFile: repo.ExcludeFile(),
+	}
+	area := w.AreaDir()
+	if status.Pattern, err = repo.Pattern(area); err != nil {
+		return nil, err
+	}
+	if status.Excluded, err = repo.IsExcluded(status.Pattern); err != nil {
+		return nil, err
+	}
+	if status.Ignored, err = repo.IsIgnored(ctx, area); err != nil {
+		return nil, fmt.Errorf("failed to check git ignore rules: %w", err)
+	}
+	if status.Tracked, err = repo.TrackedFiles(ctx, area); err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+	return status, nil
+}
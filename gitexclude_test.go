@@ -0,0 +1,44 @@
+package sidetable_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+)
+
+func TestWorkspaceRunAddsGitExclude(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	configPath := filepath.Join(root, ".sidetable.yml")
+	require.NoError(t, os.WriteFile(
+		configPath,
+		[]byte("version: 1\ndirectory: .sidetable\ngit_exclude: true\ntools:\n  hello:\n    run: echo\n"),
+		0o600,
+	))
+
+	ws, err := sidetable.Open(root, sidetable.WithConfigPath(configPath))
+	require.NoError(t, err)
+
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}
+	require.NoError(t, ws.Run(context.Background(), "hello", nil, opts))
+	require.NoError(t, ws.Run(context.Background(), "hello", nil, opts))
+
+	data, err := os.ReadFile(filepath.Join(root, ".git", "info", "exclude"))
+	require.NoError(t, err)
+	require.Equal(t, "# added by sidetable\n/.sidetable/\n", string(data))
+}
+
+func TestWorkspaceEnsureGitExcludeOutsideRepository(t *testing.T) {
+	ws := setupTestWorkspace(t, nil, nil)
+
+	changed, err := ws.EnsureGitExclude()
+	require.NoError(t, err)
+	require.False(t, changed)
+}
@@ -0,0 +1,324 @@
+package sidetable
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/filelock"
+	"github.com/sushichan044/sidetable/internal/fileutil"
+	"github.com/sushichan044/sidetable/internal/redact"
+	"github.com/sushichan044/sidetable/internal/registry"
+)
+
+const (
+	historyDirName  = "history"
+	historyFilePerm = 0o600
+	historyDirPerm  = 0o755
+	// historyKeyLength is the number of hex characters of the root hash used in history file names.
+	historyKeyLength = 16
+	// historyLockSuffix names the lock file guarding a history file while it is appended to or trimmed.
+	historyLockSuffix = ".lock"
+	// historyTrimSize is the size at which a history file is trimmed.
+	historyTrimSize = 4 << 20
+	// historyKeepSize is the size of the newest records kept when a history file is trimmed.
+	// It leaves room for many more runs before the file needs trimming again.
+	historyKeepSize = historyTrimSize / 2
+
+	// exitCodeNotStarted is recorded when the program could not be started.
+	exitCodeNotStarted = -1
+)
+
+// ErrHistoryEmpty is returned by LastInvocation when no invocation has been recorded for the workspace.
+var ErrHistoryEmpty = errors.New("no invocation recorded in this workspace")
+
+// ErrHistoryRedacted is returned by LastInvocation when the recorded arguments were redacted and cannot be replayed.
+var ErrHistoryRedacted = errors.New("the recorded invocation has redacted arguments; run it explicitly")
+
+// Origin identifies what started an invocation.
+type Origin string
+
+const (
+	// OriginAPI marks invocations started through the Go API without an explicit origin.
+	OriginAPI Origin = "api"
+	// OriginCLI marks invocations started from the command line.
+	OriginCLI Origin = "cli"
+	// OriginMCP marks invocations started by an MCP client.
+	OriginMCP Origin = "mcp"
+)
+
+// HistoryRecord is a recorded invocation. Values that look like secrets are redacted before recording.
+type HistoryRecord struct {
+	// Entry is the tool or alias name as invoked.
+	Entry string `json:"entry"`
+	Tool  string `json:"tool"`
+	// UserArgs are the arguments given by the user.
+	UserArgs []string `json:"user_args"`
+	Program  string   `json:"program"`
+	Path     string   `json:"path,omitempty"`
+	// Args is the full argv passed to Program, including injected arguments.
+	Args []string `json:"args"`
+	// Env holds the variables set or changed for the invocation.
+	Env      map[string]string `json:"env,omitempty"`
+	Cwd      string            `json:"cwd"`
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration_ns"`
+	// ExitCode is -1 when the program could not be started.
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Origin   Origin `json:"origin"`
+	// Exec is set when the program replaced the sidetable process. Its exit code and duration are then unknown.
+	Exec bool `json:"exec,omitempty"`
+}
+
+// Succeeded reports whether the invocation exited with status zero.
+func (r HistoryRecord) Succeeded() bool {
+	return r.ExitCode == 0 && r.Error == ""
+}
+
+// HistoryFilter selects history records. Zero fields match everything.
+type HistoryFilter struct {
+	// Entry matches records whose entry or tool equals it.
+	Entry string
+	// Succeeded matches records by outcome when set.
+	Succeeded *bool
+	Since     time.Time
+	Until     time.Time
+	// Limit keeps only the most recent records when positive.
+	Limit int
+}
+
+// Match reports whether record passes the filter, ignoring Limit.
+func (f HistoryFilter) Match(record HistoryRecord) bool {
+	if f.Entry != "" && record.Entry != f.Entry && record.Tool != f.Entry {
+		return false
+	}
+	if f.Succeeded != nil && record.Succeeded() != *f.Succeeded {
+		return false
+	}
+	if !f.Since.IsZero() && record.Start.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Start.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// History returns the recorded invocations of the workspace matching filter, oldest first.
+func (w *Workspace) History(filter HistoryFilter) ([]HistoryRecord, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+
+	path, err := w.historyPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record HistoryRecord
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if filter.Match(record) {
+			records = append(records, record)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
+
+// LastInvocation returns the most recent invocation recorded in the workspace, for running it again.
+// When entry is not empty, the most recent invocation of that tool or alias is returned.
+//
+// It returns ErrHistoryEmpty when nothing matches, and the record together with ErrHistoryRedacted
+// when its user arguments were redacted and cannot be replayed.
+func (w *Workspace) LastInvocation(entry string) (HistoryRecord, error) {
+	records, err := w.History(HistoryFilter{Entry: entry, Limit: 1})
+	if err != nil {
+		return HistoryRecord{}, err
+	}
+	if len(records) == 0 {
+		if entry != "" {
+			return HistoryRecord{}, fmt.Errorf("%w for %q", ErrHistoryEmpty, entry)
+		}
+		return HistoryRecord{}, ErrHistoryEmpty
+	}
+
+	last := records[0]
+	if slices.ContainsFunc(last.UserArgs, func(arg string) bool {
+		return strings.Contains(arg, redact.Placeholder)
+	}) {
+		return last, ErrHistoryRedacted
+	}
+	return last, nil
+}
+
+// historyPath returns the history file of the workspace.
+// Each workspace root has its own file, named after a hash of the root.
+func (w *Workspace) historyPath() (string, error) {
+	root, err := filepath.Abs(w.rootDir)
+	if err != nil {
+		return "", err
+	}
+	dir, err := registry.StateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, historyDirName, hex.EncodeToString(sum[:])[:historyKeyLength]+".jsonl"), nil
+}
+
+// recordHistory appends an invocation to the history file.
+// Recording is best effort: a broken or unwritable history must not change the outcome of a run.
+func (w *Workspace) recordHistory(
+	name string,
+	userArgs []string,
+	inv Invocation,
+	opts InvokeOptions,
+	start time.Time,
+	runErr error,
+) {
+	_ = w.appendHistory(newHistoryRecord(name, userArgs, inv, opts, start, runErr))
+}
+
+// recordExec appends an invocation that is about to replace the sidetable process to the history file.
+func (w *Workspace) recordExec(name string, userArgs []string, inv Invocation, opts InvokeOptions, start time.Time) {
+	record := newHistoryRecord(name, userArgs, inv, opts, start, nil)
+	record.Duration = 0
+	record.Exec = true
+	_ = w.appendHistory(record)
+}
+
+func newHistoryRecord(
+	name string,
+	userArgs []string,
+	inv Invocation,
+	opts InvokeOptions,
+	start time.Time,
+	runErr error,
+) HistoryRecord {
+	record := HistoryRecord{
+		Entry:    name,
+		Tool:     inv.ToolName,
+		UserArgs: redact.Args(userArgs),
+		Program:  inv.Program,
+		Path:     inv.Path,
+		Args:     redact.Args(inv.Args),
+		Start:    start.UTC(),
+		Duration: time.Since(start),
+		Origin:   opts.Origin,
+	}
+	if record.Origin == "" {
+		record.Origin = OriginAPI
+	}
+	record.Cwd, _ = os.Getwd()
+	for _, change := range EnvDiff(os.Environ(), inv.Env) {
+		if change.Kind == EnvChangeRemoved {
+			continue
+		}
+		if record.Env == nil {
+			record.Env = make(map[string]string)
+		}
+		record.Env[change.Key] = redact.Value(change.Key, change.Value)
+	}
+	if runErr != nil {
+		record.ExitCode = exitCodeNotStarted
+		record.Error = runErr.Error()
+		if invErr, ok := AsInvocationError(runErr); ok {
+			record.ExitCode = invErr.Code
+			record.Error = ""
+		}
+	}
+	return record
+}
+
+// appendHistory appends record to the history file, and trims the file once it grows beyond historyTrimSize.
+func (w *Workspace) appendHistory(record HistoryRecord) error {
+	path, err := w.historyPath()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), historyDirPerm); err != nil {
+		return err
+	}
+
+	// Trimming replaces the file, so appending must not happen at the same time.
+	lock, err := filelock.Acquire(path + historyLockSuffix)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, historyFilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	info, err := f.Stat()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || info.Size() <= historyTrimSize {
+		return err
+	}
+	return trimHistory(path)
+}
+
+// trimHistory rewrites the history file at path with only its newest records that fit in historyKeepSize.
+// The newest record is always kept.
+func trimHistory(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := bytes.SplitAfter(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	size := 0
+	start := len(lines)
+	for start > 0 && (start == len(lines) || size+len(lines[start-1]) <= historyKeepSize) {
+		start--
+		size += len(lines[start])
+	}
+	if start == 0 {
+		return nil
+	}
+	kept := bytes.Join(lines[start:], nil)
+	return fileutil.WriteFileAtomic(path, append(kept, '\n'), historyFilePerm)
+}
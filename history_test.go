@@ -0,0 +1,138 @@
+package sidetable_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func TestWorkspaceRunRecordsHistory(t *testing.T) {
+	t.Setenv("SECRET_PARENT", "kept-out")
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"hello": {
+				Run: "echo",
+				Env: map[string]string{"API_TOKEN": "s3cret", "GREETING": "hi"},
+			},
+			"fail":    {Run: "sh", Args: config.Args{Prepend: []string{"-c", "exit 3"}}},
+			"missing": {Run: "sidetable-test-no-such-program"},
+		},
+		map[string]config.Alias{"hi": {Tool: "hello"}},
+	)
+
+	ctx := context.Background()
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard, Origin: sidetable.OriginCLI}
+	require.NoError(t, ws.Run(ctx, "hi", []string{"--token", "abc", "--password=def", "plain"}, opts))
+	require.Error(t, ws.Run(ctx, "fail", nil, sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}))
+	require.Error(t, ws.Run(ctx, "missing", nil, opts))
+
+	records, err := ws.History(sidetable.HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	hello := records[0]
+	require.Equal(t, "hi", hello.Entry)
+	require.Equal(t, "hello", hello.Tool)
+	require.Equal(t, "echo", hello.Program)
+	require.Equal(t, []string{"--token", "[REDACTED]", "--password=[REDACTED]", "plain"}, hello.UserArgs)
+	require.Equal(t, map[string]string{"API_TOKEN": "[REDACTED]", "GREETING": "hi"}, hello.Env)
+	require.Equal(t, sidetable.OriginCLI, hello.Origin)
+	require.True(t, hello.Succeeded())
+	require.NotEmpty(t, hello.Cwd)
+
+	require.Equal(t, 3, records[1].ExitCode)
+	require.Equal(t, sidetable.OriginAPI, records[1].Origin)
+	require.False(t, records[1].Succeeded())
+
+	require.Equal(t, -1, records[2].ExitCode)
+	require.Contains(t, records[2].Error, "sidetable-test-no-such-program")
+
+	failed := false
+	records, err = ws.History(sidetable.HistoryFilter{Succeeded: &failed})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	records, err = ws.History(sidetable.HistoryFilter{Entry: "hello"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	records, err = ws.History(sidetable.HistoryFilter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	records, err = ws.History(sidetable.HistoryFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Equal(t, "missing", records[0].Entry)
+}
+
+func TestWorkspaceLastInvocation(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"hello": {Run: "echo"}, "bye": {Run: "echo"}}, nil)
+
+	_, err := ws.LastInvocation("")
+	require.ErrorIs(t, err, sidetable.ErrHistoryEmpty)
+
+	ctx := context.Background()
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}
+	require.NoError(t, ws.Run(ctx, "hello", []string{"world"}, opts))
+	require.NoError(t, ws.Run(ctx, "bye", []string{"--api-key=x"}, opts))
+
+	last, err := ws.LastInvocation("hello")
+	require.NoError(t, err)
+	require.Equal(t, []string{"world"}, last.UserArgs)
+
+	last, err = ws.LastInvocation("")
+	require.ErrorIs(t, err, sidetable.ErrHistoryRedacted)
+	require.Equal(t, "bye", last.Entry)
+}
+
+func TestWorkspaceHistoryIsTrimmed(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"hello": {Run: "echo"}}, nil)
+	stateDir := t.TempDir()
+	t.Setenv("SIDETABLE_STATE_DIR", stateDir)
+
+	ctx := context.Background()
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}
+	require.NoError(t, ws.Run(ctx, "hello", nil, opts))
+	files, err := filepath.Glob(filepath.Join(stateDir, "history", "*.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// Fill the history with old records until it is large enough to be trimmed on the next run.
+	old, err := json.Marshal(sidetable.HistoryRecord{Entry: "old", Program: strings.Repeat("x", 1024)})
+	require.NoError(t, err)
+	f, err := os.OpenFile(files[0], os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	const oldRecords = 5000
+	_, err = f.Write(bytes.Repeat(append(old, '\n'), oldRecords))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.NoError(t, ws.Run(ctx, "hello", []string{"last"}, opts))
+
+	// The file is trimmed to half the trim size, so that the next runs do not trim it again.
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.LessOrEqual(t, info.Size(), int64(2<<20))
+	records, err := ws.History(sidetable.HistoryFilter{})
+	require.NoError(t, err)
+	require.Less(t, len(records), oldRecords)
+	require.Equal(t, "old", records[0].Entry)
+	require.Equal(t, []string{"last"}, records[len(records)-1].UserArgs)
+
+	require.NoError(t, ws.Run(ctx, "hello", nil, opts))
+	after, err := ws.History(sidetable.HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, after, len(records)+1)
+}
@@ -3,7 +3,13 @@ package builtin
 // IsReservedName returns true when name is reserved as a built-in CLI command.
 func IsReservedName(name string) bool {
 	switch name {
-	case "list", "completion", "init", "help", "mcp":
+	case "list", "completion", "init", "help", "mcp",
+		"validate", "migrate", "explain", "edit",
+		"tool", "alias", "dir", "path",
+		"status", "prune", "doctor",
+		"export", "import", "workspaces",
+		"history", "again",
+		"start", "stop", "restart", "logs":
 		return true
 	default:
 		return false
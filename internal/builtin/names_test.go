@@ -9,7 +9,15 @@ import (
 )
 
 func TestIsReservedName(t *testing.T) {
-	for _, name := range []string{"list", "completion", "init", "help", "mcp"} {
+	for _, name := range []string{
+		"list", "completion", "init", "help", "mcp",
+		"validate", "migrate", "explain", "edit",
+		"tool", "alias", "dir", "path",
+		"status", "prune", "doctor",
+		"export", "import", "workspaces",
+		"history", "again",
+		"start", "stop", "restart", "logs",
+	} {
 		require.True(t, builtin.IsReservedName(name), "expected %q to be reserved", name)
 	}
 	require.False(t, builtin.IsReservedName("ghq"))
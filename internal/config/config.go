@@ -1,12 +1,18 @@
 package config
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
 
 	"github.com/sushichan044/sidetable/internal/xdg"
 )
@@ -14,30 +20,74 @@ import (
 var (
 	ErrConfigMissing = errors.New("config file not found. Please run `sidetable init` to create one")
 	ErrEntryUnknown  = errors.New("entry not found")
+	// ErrTimeoutInvalid is returned for timeouts that are not positive Go durations.
+	ErrTimeoutInvalid = errors.New("timeout must be a positive duration such as 30s or 5m")
+	// ErrRetryDelayInvalid is returned for retry delays that are not non-negative Go durations.
+	ErrRetryDelayInvalid = errors.New("retry delay must be a duration such as 500ms or 2s")
 )
 
 // Config represents configuration file structure.
 type Config struct {
+	Version   int              `yaml:"version"`
 	Directory string           `yaml:"directory"`
+	Path      []string         `yaml:"path"`
 	Tools     map[string]Tool  `yaml:"tools"`
 	Aliases   map[string]Alias `yaml:"aliases"`
-	FilePath  string           `yaml:"-"`
+	Groups    map[string]Group `yaml:"groups"`
+	// GitExclude adds the directory to .git/info/exclude of the enclosing repository when a tool runs.
+	GitExclude bool   `yaml:"git_exclude"`
+	FilePath   string `yaml:"-"`
+	// Warnings holds non-fatal problems found while loading, such as an outdated version.
+	Warnings []string `yaml:"-"`
 }
 
 // Tool represents a tool definition.
 type Tool struct {
 	Run          string            `yaml:"run"`
+	Path         []string          `yaml:"path"`
 	Args         Args              `yaml:"args"`
 	Env          map[string]string `yaml:"env"`
 	Description  string            `yaml:"description"`
 	Instructions string            `yaml:"instructions"`
+	Tags         []string          `yaml:"tags"`
+	// Timeout limits how long the tool may run, as a Go duration such as "30s".
+	Timeout string `yaml:"timeout"`
+	// Exec replaces the sidetable process with the tool when it is run from the command line on Unix.
+	Exec bool `yaml:"exec"`
+	// Retry runs the tool again when it fails.
+	Retry Retry `yaml:"retry"`
+	// Lock is "exclusive", "shared" or "none" (the default). It controls concurrent runs of the tool.
+	Lock string `yaml:"lock"`
+	// Service marks a long-running tool managed by the start, stop, restart, status and logs commands.
+	Service bool `yaml:"service"`
+	// StopSignal is the signal that stops the service gracefully, such as "SIGTERM" (the default) or "SIGINT".
+	StopSignal string `yaml:"stop_signal"`
+	// Platforms overrides run, args and env per GOOS, GOARCH or "GOOS/GOARCH".
+	Platforms map[string]PlatformOverride `yaml:"platforms"`
 }
 
 // Alias represents an alias definition.
 type Alias struct {
-	Tool        string `yaml:"tool"`
-	Args        Args   `yaml:"args"`
-	Description string `yaml:"description"`
+	Tool        string   `yaml:"tool"`
+	Args        Args     `yaml:"args"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+	// Timeout overrides the timeout of the target tool.
+	Timeout string `yaml:"timeout"`
+	// Lock overrides the lock of the target tool.
+	Lock string `yaml:"lock"`
+}
+
+// Retry configures how a failing tool is run again.
+type Retry struct {
+	// Attempts is the total number of runs, including the first one. Values below 2 disable retries.
+	Attempts int `yaml:"attempts"`
+	// Delay is the wait before the first retry, as a Go duration such as "1s".
+	Delay string `yaml:"delay"`
+	// Backoff multiplies the delay after each retry, up to five minutes. Zero keeps the delay constant.
+	Backoff float64 `yaml:"backoff"`
+	// OnExitCodes limits retries to these exit codes. Empty retries any non-zero exit code.
+	OnExitCodes []int `yaml:"on_exit_codes"`
 }
 
 // Args represents user-arg injection configuration.
@@ -52,10 +102,23 @@ type ResolvedEntry struct {
 	Tool      Tool
 	AliasName string
 	AliasArgs *Args
+	// Timeout is the alias timeout if set, otherwise the tool timeout. Zero means no timeout.
+	Timeout time.Duration
+	// Lock is the alias lock if set, otherwise the tool lock. Empty means no lock.
+	Lock string
 }
 
 const configDirEnv = "SIDETABLE_CONFIG_DIR"
 
+// ProjectConfigFileName is the name of a project-level config file in the workspace root.
+// Once trusted, it is used instead of the global config.
+const ProjectConfigFileName = ".sidetable.yml"
+
+// ProjectConfigPath returns the project-level config path for the workspace rooted at root.
+func ProjectConfigPath(root string) string {
+	return filepath.Join(root, ProjectConfigFileName)
+}
+
 // FindConfigPath returns the config path, erroring if it does not exist.
 // This is used for commands that require an existing config.
 func FindConfigPath() (string, error) {
@@ -91,66 +154,182 @@ func defaultConfigPathFromDir(dir string) string {
 	return filepath.Join(dir, "config.yml")
 }
 
+// LoadOption configures Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	lenient bool
+	strict  bool
+}
+
+// WithLenient makes Load ignore unknown fields instead of reporting them.
+// This allows older versions of sidetable to read configs written for newer ones.
+func WithLenient() LoadOption {
+	return func(o *loadOptions) {
+		o.lenient = true
+		o.strict = false
+	}
+}
+
+// WithStrict makes Load report unknown fields as validation issues instead of warnings.
+func WithStrict() LoadOption {
+	return func(o *loadOptions) {
+		o.strict = true
+		o.lenient = false
+	}
+}
+
 // Load reads and validates config from path.
-func Load(path string) (*Config, error) {
+// Unknown fields are reported in Warnings, or as validation issues when WithStrict is given.
+func Load(path string, opts ...LoadOption) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-	if err = yaml.Unmarshal(data, &cfg); err != nil {
+	return Parse(data, path, opts...)
+}
+
+// Parse decodes and validates config source as if it were read from path.
+// Diagnostics refer to path, which does not need to exist.
+func Parse(data []byte, path string, opts ...LoadOption) (*Config, error) {
+	loadOpts := loadOptions{}
+	for _, opt := range opts {
+		opt(&loadOpts)
+	}
+	file := filepath.Clean(path)
+
+	parsed, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, newYAMLDiagnosticError(err, file, data)
+	}
+	migrations, err := migrateFile(parsed)
+	if err != nil {
 		return nil, err
 	}
-	cfg.FilePath = filepath.Clean(path)
 
-	if err = cfg.Validate(); err != nil {
+	var cfg Config
+	root := documentBody(parsed)
+	if root != nil {
+		if err = yaml.NodeToValue(root, &cfg); err != nil {
+			return nil, newYAMLDiagnosticError(err, file, data)
+		}
+	}
+	cfg.FilePath = file
+	if warning := outdatedVersionWarning(migrations); warning != "" {
+		cfg.Warnings = append(cfg.Warnings, warning)
+	}
+
+	src := &sourceFile{root: root, lines: splitLines(data)}
+	if err = cfg.validate(src, loadOpts.strict); err != nil {
 		return nil, err
 	}
+	if !loadOpts.strict && !loadOpts.lenient {
+		cfg.Warnings = append(cfg.Warnings, unknownFieldWarnings(src, file)...)
+	}
 
 	return &cfg, nil
 }
 
 // Validate ensures config follows the specification.
 func (c *Config) Validate() error {
+	return c.validate(nil, false)
+}
+
+// validate runs schema validation and maps issues back to source positions when src is given.
+// When strict is set, keys in src that do not map to a config field are reported as well.
+func (c *Config) validate(src *sourceFile, strict bool) error {
 	issues := c.validateWithSchema()
+	if strict && src != nil {
+		issues = append(issues, unknownFieldIssues(src.root)...)
+		sortIssues(issues)
+	}
 	if len(issues) == 0 {
 		return nil
 	}
 
+	file := ""
+	if c != nil {
+		file = c.FilePath
+	}
+
 	errs := make([]error, 0, len(issues))
 	for _, issue := range issues {
-		errs = append(errs, newValidationIssueError(issue))
+		errs = append(errs, newIssueDiagnosticError(issue, file, src))
 	}
 
 	return errors.Join(errs...)
 }
 
-// ResolveEntry resolves a tool or alias name.
+// ResolveEntry resolves a tool or alias name for the current platform.
+// Grouped entries may be named with dots ("db.migrate") or words ("db migrate").
 func (c *Config) ResolveEntry(name string) (*ResolvedEntry, error) {
-	if tool, ok := c.Tools[name]; ok {
-		return &ResolvedEntry{
-			ToolName:  name,
-			Tool:      tool,
-			AliasName: "",
-			AliasArgs: nil,
-		}, nil
+	return c.ResolveEntryForPlatform(name, CurrentPlatform())
+}
+
+// ResolveEntryForPlatform resolves a tool or alias name with platform overrides for p applied.
+// It returns ErrToolUnavailable when the tool has no definition for p.
+func (c *Config) ResolveEntryForPlatform(name string, p Platform) (*ResolvedEntry, error) {
+	name = NormalizeEntryName(name)
+	resolved := &ResolvedEntry{}
+	timeout, lock := "", ""
+	if _, ok := c.Tools[name]; ok {
+		resolved.ToolName = name
+	} else {
+		alias, found := c.Aliases[name]
+		if !found {
+			return nil, ErrEntryUnknown
+		}
+		if _, found = c.Tools[alias.Tool]; !found {
+			return nil, ErrEntryUnknown
+		}
+		resolved.ToolName = alias.Tool
+		resolved.AliasName = name
+		resolved.AliasArgs = &alias.Args
+		timeout = alias.Timeout
+		lock = alias.Lock
 	}
-	alias, ok := c.Aliases[name]
-	if !ok {
-		return nil, ErrEntryUnknown
+
+	tool := c.Tools[resolved.ToolName]
+	if !tool.AvailableOn(p) {
+		return nil, fmt.Errorf("%w: %s on %s", ErrToolUnavailable, resolved.ToolName, p)
 	}
-	tool, ok := c.Tools[alias.Tool]
-	if !ok {
-		return nil, ErrEntryUnknown
+	resolved.Tool = tool.ForPlatform(p)
+
+	if timeout == "" {
+		timeout = resolved.Tool.Timeout
+	}
+	resolved.Lock = cmp.Or(lock, resolved.Tool.Lock)
+	var err error
+	if resolved.Timeout, err = ParseTimeout(timeout); err != nil {
+		return nil, err
 	}
 
-	return &ResolvedEntry{
-		ToolName:  alias.Tool,
-		Tool:      tool,
-		AliasName: name,
-		AliasArgs: &alias.Args,
-	}, nil
+	return resolved, nil
+}
+
+// ParseTimeout parses a tool or alias timeout. An empty string means no timeout.
+func ParseTimeout(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrTimeoutInvalid, s)
+	}
+	return d, nil
+}
+
+// ParseRetryDelay parses a retry delay. An empty string means no delay.
+func ParseRetryDelay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrRetryDelayInvalid, s)
+	}
+	return d, nil
 }
 
 // ToolNames returns sorted tool names.
@@ -162,3 +341,17 @@ func (c *Config) ToolNames() []string {
 	sort.Strings(names)
 	return names
 }
+
+// IsValidEntryName reports whether name can be used for a tool or alias.
+// Tool names are used as directory names in the tool area, so they must be a single path element.
+func IsValidEntryName(name string) bool {
+	return name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// stopSignals are the signal names accepted for stop_signal.
+var stopSignals = []string{"SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT", "SIGKILL", "SIGUSR1", "SIGUSR2"}
+
+// IsStopSignal reports whether name is a signal accepted for stop_signal. An empty name selects the default.
+func IsStopSignal(name string) bool {
+	return name == "" || slices.Contains(stopSignals, name)
+}
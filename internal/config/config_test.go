@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,6 +16,11 @@ import (
 	"github.com/sushichan044/sidetable/internal/config"
 )
 
+const (
+	msgToolConflictsWithBuiltin  = `tool conflicts with builtin command; rename it with "sidetable tool rename"`
+	msgAliasConflictsWithBuiltin = `alias conflicts with builtin command; rename it with "sidetable alias rename"`
+)
+
 func TestResolvePath(t *testing.T) {
 	base := t.TempDir()
 	envDir := filepath.Join(base, "env")
@@ -134,7 +140,7 @@ func TestValidate(t *testing.T) {
 				"list": {Run: "ghq"},
 			},
 		}
-		requireHasIssue(t, cfg.Validate(), `tools["list"]`, "tool conflicts with builtin command")
+		requireHasIssue(t, cfg.Validate(), `tools["list"]`, msgToolConflictsWithBuiltin)
 	})
 
 	t.Run("alias tool required", func(t *testing.T) {
@@ -201,7 +207,7 @@ func TestValidate(t *testing.T) {
 				"list": {Tool: "a"},
 			},
 		}
-		requireHasIssue(t, cfg.Validate(), `aliases["list"]`, "alias conflicts with builtin command")
+		requireHasIssue(t, cfg.Validate(), `aliases["list"]`, msgAliasConflictsWithBuiltin)
 	})
 
 	t.Run("collects multiple validation errors", func(t *testing.T) {
@@ -222,8 +228,8 @@ func TestValidate(t *testing.T) {
 		err := cfg.Validate()
 		require.Error(t, err)
 		requireHasIssue(t, err, `tools["list"].run`, "tool run must not contain spaces")
-		requireHasIssue(t, err, `tools["list"]`, "tool conflicts with builtin command")
-		requireHasIssue(t, err, `aliases["help"]`, "alias conflicts with builtin command")
+		requireHasIssue(t, err, `tools["list"]`, msgToolConflictsWithBuiltin)
+		requireHasIssue(t, err, `aliases["help"]`, msgAliasConflictsWithBuiltin)
 		requireHasIssue(t, err, `aliases["help"].tool`, "alias tool not found")
 	})
 }
@@ -334,6 +340,12 @@ tools:
       prepend: ["-l"]
       append:
         - "-v"
+    exec: true
+    retry:
+      attempts: 3
+      delay: 500ms
+      backoff: 2
+      on_exit_codes: [75]
 aliases:
   gg:
     tool: ghq
@@ -358,6 +370,8 @@ aliases:
 	require.Equal(t, map[string]string{"A": "a", "B": "b"}, tool.Env)
 	require.ElementsMatch(t, []string{"-l"}, tool.Args.Prepend)
 	require.ElementsMatch(t, []string{"-v"}, tool.Args.Append)
+	require.True(t, tool.Exec)
+	require.Equal(t, config.Retry{Attempts: 3, Delay: "500ms", Backoff: 2, OnExitCodes: []int{75}}, tool.Retry)
 
 	alias, ok := cfg.Aliases["gg"]
 	require.True(t, ok)
@@ -477,3 +491,622 @@ func TestResolveEntryWithAliasInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_ReportsIssuePositions(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `directory: .private
+tools:
+  a:
+    run: "bad run"
+aliases:
+  list:
+    tool: missing
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, err := config.Load(path)
+	require.Error(t, err)
+
+	diags := config.Diagnostics(err)
+	byPath := make(map[string]config.Diagnostic, len(diags))
+	for _, d := range diags {
+		byPath[d.Path+"|"+d.Message] = d
+	}
+
+	run := byPath[`tools["a"].run|tool run must not contain spaces`]
+	assert.Equal(t, path, run.File)
+	assert.Equal(t, 4, run.Line)
+	assert.Equal(t, 10, run.Column)
+	assert.Equal(t, " 4 |     run: \"bad run\"\n   |          ^", run.Snippet)
+
+	builtinConflict := byPath[`aliases["list"]|`+msgAliasConflictsWithBuiltin]
+	assert.Equal(t, 6, builtinConflict.Line)
+	assert.Equal(t, 3, builtinConflict.Column)
+
+	target := byPath[`aliases["list"].tool|alias tool not found`]
+	assert.Equal(t, 7, target.Line)
+	assert.Equal(t, 11, target.Column)
+	assert.Equal(t, path+`:7:11: aliases["list"].tool: alias tool not found`, target.String())
+}
+
+func TestLoad_ReportsMissingKeyAtParent(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `directory: .private
+tools:
+  a:
+    description: "no run"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, err := config.Load(path)
+	diags := config.Diagnostics(err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, `tools["a"].run`, diags[0].Path)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, 3, diags[0].Column)
+}
+
+func TestLoad_InvalidYAMLHasPosition(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	require.NoError(t, os.WriteFile(path, []byte("directory: .private\ntools:\n  - name: bad\n"), 0o644))
+
+	_, err := config.Load(path)
+	diag, ok := config.AsDiagnostic(err)
+	require.True(t, ok)
+	assert.Equal(t, path, diag.File)
+	assert.Positive(t, diag.Line)
+	assert.NotEmpty(t, diag.Snippet)
+}
+
+func TestValidate_WithoutSourceHasNoPosition(t *testing.T) {
+	cfg := &config.Config{Tools: map[string]config.Tool{"a": {Run: "a"}}}
+
+	diags := config.Diagnostics(cfg.Validate())
+	require.Len(t, diags, 1)
+	assert.Equal(t, "directory: directory is required", diags[0].String())
+	assert.Zero(t, diags[0].Line)
+	assert.Empty(t, diags[0].Snippet)
+}
+
+func TestLoad_RejectsUnknownFields(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `directory: .private
+tools:
+  ghq:
+    run: ghq
+    descripton: "typo"
+    args:
+      prepnd: ["-l"]
+alias:
+  gg:
+    tool: ghq
+aliases:
+  x:
+    tool: ghq
+    arg:
+      append: ["get"]
+    zzzzzz: true
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, err := config.Load(path, config.WithStrict())
+	require.Error(t, err)
+
+	requireHasIssue(t, err, `tools["ghq"].descripton`, `unknown field "descripton" (did you mean "description"?)`)
+	requireHasIssue(t, err, `tools["ghq"].args.prepnd`, `unknown field "prepnd" (did you mean "prepend"?)`)
+	requireHasIssue(t, err, `alias`, `unknown field "alias" (did you mean "aliases"?)`)
+	requireHasIssue(t, err, `aliases["x"].arg`, `unknown field "arg" (did you mean "args"?)`)
+	requireHasIssue(t, err, `aliases["x"].zzzzzz`, `unknown field "zzzzzz"`)
+
+	for _, diag := range config.Diagnostics(err) {
+		if diag.Path == `tools["ghq"].descripton` {
+			assert.Equal(t, 5, diag.Line)
+			assert.Equal(t, 5, diag.Column)
+		}
+	}
+}
+
+func TestLoad_WarnsAboutUnknownFieldsByDefault(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `version: 1
+directory: .private
+tools:
+  ghq:
+    run: ghq
+    descripton: "typo"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, "ghq", cfg.Tools["ghq"].Run)
+	require.Len(t, cfg.Warnings, 2)
+	assert.Equal(
+		t,
+		path+`:6:5: tools["ghq"].descripton: unknown field "descripton" (did you mean "description"?)`,
+		cfg.Warnings[0],
+	)
+	assert.Contains(t, cfg.Warnings[1], "SIDETABLE_LENIENT_CONFIG")
+}
+
+func TestLoad_LenientIgnoresUnknownFields(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `version: 1
+directory: .private
+future_setting: true
+tools:
+  ghq:
+    run: ghq
+    descripton: "typo"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err := config.Load(path, config.WithLenient())
+	require.NoError(t, err)
+	require.Equal(t, "ghq", cfg.Tools["ghq"].Run)
+	require.Empty(t, cfg.Warnings)
+}
+
+func TestLoad_UpgradesOutdatedVersionInMemory(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+
+	content := `# comment
+directory: .private
+tools:
+  a:
+    run: "bad run"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, err := config.Load(path)
+	diags := config.Diagnostics(err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, 5, diags[0].Line, "positions must refer to the original file")
+
+	require.NoError(t, os.WriteFile(path, []byte("directory: .private\ntools: {}\n"), 0o644))
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, config.CurrentVersion, cfg.Version)
+	assert.Empty(t, cfg.Warnings, "adding the version key alone changes nothing worth warning about")
+}
+
+func TestLoad_CurrentVersionHasNoWarnings(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\ndirectory: .private\ntools: {}\n"), 0o644))
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cfg.Version)
+	assert.Empty(t, cfg.Warnings)
+}
+
+func TestLoad_RejectsNewerVersion(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 99\ndirectory: .private\n"), 0o644))
+
+	_, err := config.Load(path)
+	requireHasIssue(t, err, "version", "version is newer than supported version 1; please upgrade sidetable")
+
+	diag, ok := config.AsDiagnostic(err)
+	require.True(t, ok)
+	assert.Equal(t, 1, diag.Line)
+	assert.Equal(t, 10, diag.Column)
+}
+
+func TestMigrateSource(t *testing.T) {
+	t.Run("adds version and keeps comments", func(t *testing.T) {
+		src := `# Required. Project-local tool area name.
+directory: ".private" # inline
+
+tools:
+  a:
+    # Program to run.
+    run: echo
+`
+		out, applied, err := config.MigrateSource([]byte(src))
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		assert.Equal(t, 0, applied[0].From)
+		assert.Equal(t, 1, applied[0].To)
+		assert.Equal(t, "version: 1\n"+src, string(out))
+	})
+
+	t.Run("single key document", func(t *testing.T) {
+		out, _, err := config.MigrateSource([]byte("directory: .private\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "version: 1\ndirectory: .private\n", string(out))
+	})
+
+	t.Run("explicit old version is replaced in place", func(t *testing.T) {
+		out, applied, err := config.MigrateSource([]byte("directory: .private\nversion: 0\n"))
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		assert.Equal(t, "directory: .private\nversion: 1\n", string(out))
+	})
+
+	t.Run("current version is unchanged", func(t *testing.T) {
+		src := []byte("version: 1\ndirectory: .private\n")
+		out, applied, err := config.MigrateSource(src)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, src, out)
+	})
+}
+
+func TestResolveEntryForPlatform(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"open": {
+				Run:  "xdg-open",
+				Args: config.Args{Prepend: []string{"--base"}, Append: []string{"--tail"}},
+				Env:  map[string]string{"A": "base", "B": "base"},
+				Platforms: map[string]config.PlatformOverride{
+					"darwin":       {Run: "open"},
+					"arm64":        {Env: map[string]string{"A": "arch"}},
+					"darwin/arm64": {Args: config.Args{Prepend: []string{"-a"}}, Env: map[string]string{"B": "pair"}},
+				},
+			},
+			"pbcopy": {
+				Platforms: map[string]config.PlatformOverride{
+					"darwin": {Run: "pbcopy"},
+				},
+			},
+		},
+		Aliases: map[string]config.Alias{
+			"copy": {Tool: "pbcopy"},
+		},
+	}
+
+	t.Run("no matching override", func(t *testing.T) {
+		resolved, err := cfg.ResolveEntryForPlatform("open", config.Platform{OS: "linux", Arch: "amd64"})
+		require.NoError(t, err)
+		assert.Equal(t, "xdg-open", resolved.Tool.Run)
+		assert.Equal(t, []string{"--base"}, resolved.Tool.Args.Prepend)
+		assert.Equal(t, map[string]string{"A": "base", "B": "base"}, resolved.Tool.Env)
+		assert.Nil(t, resolved.Tool.Platforms)
+	})
+
+	t.Run("overrides are layered from least to most specific", func(t *testing.T) {
+		resolved, err := cfg.ResolveEntryForPlatform("open", config.Platform{OS: "darwin", Arch: "arm64"})
+		require.NoError(t, err)
+		assert.Equal(t, "open", resolved.Tool.Run)
+		assert.Equal(t, []string{"-a"}, resolved.Tool.Args.Prepend)
+		assert.Equal(t, []string{"--tail"}, resolved.Tool.Args.Append)
+		assert.Equal(t, map[string]string{"A": "arch", "B": "pair"}, resolved.Tool.Env)
+		assert.Equal(t, "base", cfg.Tools["open"].Env["A"], "base config must not be mutated")
+	})
+
+	t.Run("tool only defined for other platforms", func(t *testing.T) {
+		_, err := cfg.ResolveEntryForPlatform("pbcopy", config.Platform{OS: "linux", Arch: "amd64"})
+		require.ErrorIs(t, err, config.ErrToolUnavailable)
+
+		_, err = cfg.ResolveEntryForPlatform("copy", config.Platform{OS: "linux", Arch: "amd64"})
+		require.ErrorIs(t, err, config.ErrToolUnavailable)
+
+		resolved, err := cfg.ResolveEntryForPlatform("copy", config.Platform{OS: "darwin", Arch: "amd64"})
+		require.NoError(t, err)
+		assert.Equal(t, "pbcopy", resolved.Tool.Run)
+		assert.Equal(t, "copy", resolved.AliasName)
+	})
+}
+
+func TestValidate_Platforms(t *testing.T) {
+	t.Run("platform run satisfies required run", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"a": {Platforms: map[string]config.PlatformOverride{"linux": {Run: "a"}}},
+			},
+		}
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("platforms without run", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"a": {Platforms: map[string]config.PlatformOverride{"linux": {Env: map[string]string{"A": "a"}}}},
+			},
+		}
+		requireHasIssue(t, cfg.Validate(), `tools["a"].run`, "tool run is required")
+	})
+
+	t.Run("unknown platform key", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"a": {Run: "a", Platforms: map[string]config.PlatformOverride{
+					"macos":        {Run: "b"},
+					"darwin/amd64": {Run: "c"},
+					"linux/x86":    {Run: "d"},
+				}},
+			},
+		}
+		err := cfg.Validate()
+		requireHasIssue(t, err, `tools["a"].platforms["macos"]`, "platform must be a GOOS, a GOARCH or GOOS/GOARCH")
+		requireHasIssue(t, err, `tools["a"].platforms["linux/x86"]`, "platform must be a GOOS, a GOARCH or GOOS/GOARCH")
+		require.Len(t, collectIssues(err), 2)
+	})
+
+	t.Run("platform run with spaces", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"a": {Run: "a", Platforms: map[string]config.PlatformOverride{"windows": {Run: "bad run"}}},
+			},
+		}
+		requireHasIssue(t, cfg.Validate(), `tools["a"].platforms["windows"].run`, "tool run must not contain spaces")
+	})
+}
+
+func TestValidate_Tags(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"a": {Run: "a", Tags: []string{"ok", "bad tag"}},
+		},
+		Aliases: map[string]config.Alias{
+			"b": {Tool: "a", Tags: []string{""}},
+		},
+	}
+	err := cfg.Validate()
+	requireHasIssue(t, err, `tools["a"].tags[1]`, "tag must not be empty or contain spaces")
+	requireHasIssue(t, err, `aliases["b"].tags[0]`, "tag must not be empty or contain spaces")
+	require.Len(t, collectIssues(err), 2)
+}
+
+func TestValidate_Timeout(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"a": {Run: "a", Timeout: "30s"},
+			"b": {Run: "b", Timeout: "soon"},
+		},
+		Aliases: map[string]config.Alias{
+			"c": {Tool: "a", Timeout: "-1s"},
+		},
+	}
+	err := cfg.Validate()
+	requireHasIssue(t, err, `tools["b"].timeout`, "timeout must be a positive duration such as 30s or 5m")
+	requireHasIssue(t, err, `aliases["c"].timeout`, "timeout must be a positive duration such as 30s or 5m")
+	require.Len(t, collectIssues(err), 2)
+}
+
+func TestValidate_Retry(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"ok": {Run: "ok", Retry: config.Retry{Attempts: 3, Delay: "1s", Backoff: 2, OnExitCodes: []int{1, 75}}},
+			"bad": {
+				Run:   "bad",
+				Retry: config.Retry{Attempts: -1, Delay: "later", Backoff: 0.5, OnExitCodes: []int{0, 256}},
+			},
+		},
+	}
+	err := cfg.Validate()
+	requireHasIssue(t, err, `tools["bad"].retry.attempts`, "retry attempts must not be negative")
+	requireHasIssue(t, err, `tools["bad"].retry.delay`, "retry delay must be a duration such as 500ms or 2s")
+	requireHasIssue(t, err, `tools["bad"].retry.backoff`, "retry backoff must be at least 1")
+	requireHasIssue(t, err, `tools["bad"].retry.on_exit_codes[0]`, "retry exit code must be between 1 and 255")
+	requireHasIssue(t, err, `tools["bad"].retry.on_exit_codes[1]`, "retry exit code must be between 1 and 255")
+	require.Len(t, collectIssues(err), 5)
+}
+
+func TestResolveEntryTimeout(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools:     map[string]config.Tool{"a": {Run: "a", Timeout: "30s"}},
+		Aliases: map[string]config.Alias{
+			"inherit":  {Tool: "a"},
+			"override": {Tool: "a", Timeout: "1m"},
+		},
+	}
+
+	for name, want := range map[string]time.Duration{
+		"a":        30 * time.Second,
+		"inherit":  30 * time.Second,
+		"override": time.Minute,
+	} {
+		resolved, err := cfg.ResolveEntry(name)
+		require.NoError(t, err)
+		require.Equal(t, want, resolved.Timeout, name)
+	}
+}
+
+func TestValidate_Lock(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"a": {Run: "a", Lock: "shared"},
+			"b": {Run: "b", Lock: "always"},
+		},
+		Aliases: map[string]config.Alias{
+			"c": {Tool: "a", Lock: "exclusive"},
+			"d": {Tool: "a", Lock: "Exclusive"},
+		},
+	}
+	err := cfg.Validate()
+	requireHasIssue(t, err, `tools["b"].lock`, "lock must be exclusive, shared or none")
+	requireHasIssue(t, err, `aliases["d"].lock`, "lock must be exclusive, shared or none")
+	require.Len(t, collectIssues(err), 2)
+}
+
+func TestValidate_Service(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"a": {Run: "a", Service: true, StopSignal: "SIGINT"},
+			"b": {Run: "b", Service: true},
+			"c": {Run: "c", Service: true, StopSignal: "TERM"},
+			"d": {Run: "d", StopSignal: "SIGTERM"},
+		},
+	}
+	err := cfg.Validate()
+	requireHasIssue(
+		t,
+		err,
+		`tools["c"].stop_signal`,
+		"stop_signal must be one of SIGTERM, SIGINT, SIGHUP, SIGQUIT, SIGKILL, SIGUSR1 or SIGUSR2",
+	)
+	requireHasIssue(t, err, `tools["d"].stop_signal`, "stop_signal requires service: true")
+	require.Len(t, collectIssues(err), 2)
+}
+
+func TestValidate_EntryNamesArePathElements(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"foo/bar": {Run: "a"},
+			`a\b`:     {Run: "b"},
+			"..":      {Run: "c"},
+			"go1.22":  {Run: "d"},
+		},
+		Aliases: map[string]config.Alias{
+			".":     {Tool: "go1.22"},
+			"x/y":   {Tool: "go1.22"},
+			"go.v1": {Tool: "go1.22"},
+		},
+	}
+	err := cfg.Validate()
+	const msg = "name must not contain / or \\ and must not be . or .."
+	requireHasIssue(t, err, `tools["foo/bar"]`, msg)
+	requireHasIssue(t, err, `tools["a\b"]`, msg)
+	requireHasIssue(t, err, `tools[".."]`, msg)
+	requireHasIssue(t, err, `aliases["."]`, msg)
+	requireHasIssue(t, err, `aliases["x/y"]`, msg)
+	require.Len(t, collectIssues(err), 5)
+}
+
+func TestResolveEntryLock(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"a": {Run: "a", Lock: "shared"},
+			"b": {Run: "b"},
+		},
+		Aliases: map[string]config.Alias{
+			"inherit":  {Tool: "a"},
+			"override": {Tool: "a", Lock: "exclusive"},
+		},
+	}
+
+	for name, want := range map[string]string{
+		"a":        "shared",
+		"b":        "",
+		"inherit":  "shared",
+		"override": "exclusive",
+	} {
+		resolved, err := cfg.ResolveEntry(name)
+		require.NoError(t, err)
+		require.Equal(t, want, resolved.Lock, name)
+	}
+}
+
+func TestValidate_Groups(t *testing.T) {
+	t.Run("valid groups", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"db.migrate":     {Run: "migrate"},
+				"db.schema.dump": {Run: "dump"},
+			},
+			Aliases: map[string]config.Alias{
+				"db.m": {Tool: "db.migrate"},
+			},
+			Groups: map[string]config.Group{
+				"db":        {Description: "Database tools"},
+				"db.schema": {Description: "Schema tools"},
+			},
+		}
+		require.NoError(t, cfg.Validate())
+		require.Equal(t, []string{"db", "db.schema"}, cfg.GroupNames())
+	})
+
+	t.Run("invalid groups", func(t *testing.T) {
+		cfg := &config.Config{
+			Directory: ".private",
+			Tools: map[string]config.Tool{
+				"db":         {Run: "psql"},
+				"db.migrate": {Run: "migrate"},
+				"a..b":       {Run: "ab"},
+				"list.all":   {Run: "ls"},
+			},
+			Groups: map[string]config.Group{
+				"db":     {},
+				"a":      {},
+				"list":   {},
+				"unused": {Description: "Nothing here"},
+			},
+		}
+		err := cfg.Validate()
+		requireHasIssue(t, err, `tools["db"]`, "entry name conflicts with group")
+		requireHasIssue(t, err, `tools["a..b"]`, "name must not contain empty group segments")
+		requireHasIssue(t, err, `groups["list"]`, "group conflicts with builtin command")
+		requireHasIssue(t, err, `groups["unused"]`, "group has no tools or aliases")
+	})
+}
+
+func TestResolveEntryAcceptsCommandWords(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"db.migrate": {Run: "migrate"},
+		},
+		Aliases: map[string]config.Alias{
+			"db.m": {Tool: "db.migrate"},
+		},
+	}
+
+	for _, name := range []string{"db.migrate", "db migrate", " db  migrate "} {
+		resolved, err := cfg.ResolveEntry(name)
+		require.NoError(t, err, name)
+		require.Equal(t, "db.migrate", resolved.ToolName)
+	}
+
+	resolved, err := cfg.ResolveEntry("db m")
+	require.NoError(t, err)
+	require.Equal(t, "db.m", resolved.AliasName)
+	require.Equal(t, "db.migrate", resolved.ToolName)
+}
+
+func TestParentGroups(t *testing.T) {
+	cfg := &config.Config{Groups: map[string]config.Group{"db.schema": {}}}
+	require.Empty(t, cfg.ParentGroups("db"))
+	require.Equal(t, []string{"db", "db.schema"}, cfg.ParentGroups("db.schema.dump"))
+	require.Equal(t, []string{"db", "schema", "dump"}, cfg.EntryWords("db.schema.dump"))
+	require.Equal(t, []string{"db", "migrate.up"}, cfg.EntryWords("db.migrate.up"))
+	require.Equal(t, []string{"db", "db.schema"}, cfg.GroupNames())
+	require.Equal(t, "db.migrate", config.NormalizeEntryName("db migrate"))
+}
+
+func TestDottedNamesWithoutGroupsStayFlat(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"go1.22":   {Run: "go1.22"},
+			"node.lts": {Run: "node"},
+			"node":     {Run: "node"},
+			"list.all": {Run: "ls"},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+	require.Empty(t, cfg.GroupNames())
+	require.Empty(t, cfg.ParentGroups("node.lts"))
+	require.Equal(t, []string{"go1.22"}, cfg.EntryWords("go1.22"))
+}
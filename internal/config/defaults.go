@@ -1,8 +1,45 @@
 package config
 
 import (
-	_ "embed"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
 )
 
-//go:embed default/config.yml
+// DefaultTemplate is the starter config used by `sidetable init` when no template is given.
+const DefaultTemplate = "default"
+
+var ErrTemplateUnknown = errors.New("unknown template")
+
+//go:embed templates/*.yml
+var templatesFS embed.FS
+
+//go:embed templates/default.yml
 var DefaultConfigYAML []byte
+
+// Templates returns the names of the built-in starter configs.
+func Templates() []string {
+	entries, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Template returns the built-in starter config called name.
+func Template(name string) ([]byte, error) {
+	data, err := templatesFS.ReadFile(path.Join("templates", name+".yml"))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: must be one of %s", ErrTemplateUnknown, name, strings.Join(Templates(), ", "))
+	}
+	return data, nil
+}
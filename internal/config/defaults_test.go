@@ -16,3 +16,17 @@ func TestDefaultConfigYAMLIsValid(t *testing.T) {
 	require.NoError(t, yaml.Unmarshal(config.DefaultConfigYAML, &cfg))
 	require.NoError(t, cfg.Validate())
 }
+
+func TestTemplatesAreValid(t *testing.T) {
+	require.Equal(t, []string{"default", "ghq", "minimal"}, config.Templates())
+
+	for _, name := range config.Templates() {
+		data, err := config.Template(name)
+		require.NoError(t, err, name)
+		_, err = config.Parse(data, name+".yml")
+		require.NoError(t, err, name)
+	}
+
+	_, err := config.Template("nope")
+	require.ErrorIs(t, err, config.ErrTemplateUnknown)
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/mattn/go-runewidth"
+
+	z "github.com/Oudwins/zog"
+
+	"github.com/sushichan044/sidetable/internal/errutils"
+)
+
+// Diagnostic describes a single config problem.
+// Line and Column are 1-based and zero when the location is unknown.
+type Diagnostic struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Location returns the "file:line:column" prefix of the diagnostic.
+// Unknown parts are omitted.
+func (d Diagnostic) Location() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc += fmt.Sprintf(":%d:%d", d.Line, d.Column)
+	}
+	return loc
+}
+
+// String formats the diagnostic as a single line without the snippet.
+//
+//	config.yml:12:11: aliases["x"].tool: alias tool not found
+func (d Diagnostic) String() string {
+	var parts []string
+	if loc := d.Location(); loc != "" {
+		parts = append(parts, loc)
+	}
+	if d.Path != "" {
+		parts = append(parts, d.Path)
+	}
+	parts = append(parts, d.Message)
+	return strings.Join(parts, ": ")
+}
+
+// AsDiagnostic extracts the Diagnostic carried by err.
+func AsDiagnostic(err error) (Diagnostic, bool) {
+	if err == nil {
+		return Diagnostic{}, false
+	}
+	if diagErr := new(diagnosticError); errors.As(err, &diagErr) {
+		return diagErr.diag, true
+	}
+	return Diagnostic{}, false
+}
+
+// Diagnostics flattens err into diagnostics.
+// Errors without location information are reported with their message only.
+func Diagnostics(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+
+	errs, _ := errutils.UnwrapJoinError(err)
+	diags := make([]Diagnostic, 0, len(errs))
+	for _, e := range errs {
+		if diag, ok := AsDiagnostic(e); ok {
+			diags = append(diags, diag)
+			continue
+		}
+		diags = append(diags, Diagnostic{Message: e.Error()})
+	}
+	return diags
+}
+
+type diagnosticError struct {
+	diag Diagnostic
+	err  error
+}
+
+func (e *diagnosticError) Error() string {
+	return e.diag.String()
+}
+
+func (e *diagnosticError) Unwrap() error {
+	return e.err
+}
+
+func newIssueDiagnosticError(issue *z.ZogIssue, file string, src *sourceFile) error {
+	msg := issue.Message
+	if msg == "" {
+		msg = issue.Error()
+	}
+
+	diag := Diagnostic{
+		File:    file,
+		Path:    issue.PathString(),
+		Message: msg,
+	}
+	if src != nil {
+		src.annotate(&diag, src.locate(issue.Path, issue.Code == issueCodeUnknownField))
+	}
+
+	return &diagnosticError{diag: diag, err: issue}
+}
+
+func newYAMLDiagnosticError(err error, file string, source []byte) error {
+	var yamlErr yaml.Error
+	if !errors.As(err, &yamlErr) {
+		return err
+	}
+
+	diag := Diagnostic{
+		File:    file,
+		Message: yamlErr.GetMessage(),
+	}
+	src := &sourceFile{lines: splitLines(source)}
+	src.annotate(&diag, yamlErr.GetToken())
+
+	return &diagnosticError{diag: diag, err: err}
+}
+
+// sourceFile holds the raw config source used to map issue paths back to positions.
+type sourceFile struct {
+	root  ast.Node
+	lines []string
+}
+
+type mapRanger interface {
+	MapRange() *ast.MapNodeIter
+}
+
+// locate returns the token that best matches the zog issue path.
+// Keys that are missing from the source resolve to their nearest existing parent.
+// Issues on scalar fields point at the value unless keyOnly is set, and issues on entries point at the key.
+func (s *sourceFile) locate(path []string, keyOnly bool) *token.Token {
+	var found *token.Token
+
+	node := s.root
+	for i, segment := range path {
+		m, ok := node.(mapRanger)
+		if !ok {
+			break
+		}
+
+		key, isEntry := unbracketKey(segment)
+		var next ast.Node
+		iter := m.MapRange()
+		for iter.Next() {
+			keyToken := iter.Key().GetToken()
+			if keyToken != nil && keyToken.Value == key {
+				found = keyToken
+				next = iter.Value()
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+
+		if i == len(path)-1 && !isEntry && !keyOnly {
+			if _, isMap := next.(mapRanger); !isMap && next.Type() != ast.NullType && next.GetToken() != nil {
+				found = next.GetToken()
+			}
+		}
+	}
+
+	return found
+}
+
+func (s *sourceFile) annotate(diag *Diagnostic, tk *token.Token) {
+	if tk == nil || tk.Position == nil || tk.Position.Line <= 0 {
+		return
+	}
+
+	diag.Line = tk.Position.Line
+	diag.Column = tk.Position.Column
+	diag.Snippet = s.snippet(diag.Line, diag.Column)
+}
+
+// snippet renders the source line with a caret under column.
+//
+//	12 |     tool: missing
+//	   |           ^
+func (s *sourceFile) snippet(line, column int) string {
+	if line > len(s.lines) {
+		return ""
+	}
+
+	text := s.lines[line-1]
+	gutter := fmt.Sprintf(" %d | ", line)
+	blank := strings.Repeat(" ", len(gutter)-len("| ")) + "| "
+
+	runes := []rune(text)
+	prefix := string(runes[:min(max(column-1, 0), len(runes))])
+	caret := strings.Repeat(" ", runewidth.StringWidth(prefix)) + "^"
+
+	return gutter + text + "\n" + blank + caret
+}
+
+func splitLines(source []byte) []string {
+	return strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n")
+}
+
+// unbracketKey converts a path segment built by bracketKey back to the map key.
+func unbracketKey(segment string) (string, bool) {
+	if strings.HasPrefix(segment, `["`) && strings.HasSuffix(segment, `"]`) {
+		return strings.TrimSuffix(strings.TrimPrefix(segment, `["`), `"]`), true
+	}
+	return segment, false
+}
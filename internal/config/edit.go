@@ -0,0 +1,342 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+const (
+	sectionTools   = "tools"
+	sectionAliases = "aliases"
+
+	// defaultIndent is used when the config has no nested mapping to copy the indentation from.
+	defaultIndent = 2
+)
+
+var (
+	ErrEntryExists = errors.New("entry already exists")
+
+	errFlowMappingEdit = errors.New("flow-style mappings cannot be edited; rewrite the section in block style")
+)
+
+// The functions below edit config source through its YAML AST, so comments and key order are preserved.
+// They do not validate the result; use Parse for that before writing it.
+
+// AddTool adds a tool definition to source.
+func AddTool(source []byte, name string, tool Tool) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		return addEntry(root, sectionTools, name, toolYAML(tool))
+	})
+}
+
+// AddAlias adds an alias definition to source.
+func AddAlias(source []byte, name string, alias Alias) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		return addEntry(root, sectionAliases, name, aliasYAML(alias))
+	})
+}
+
+// RemoveTool removes a tool definition from source.
+// Aliases targeting the tool are left in place.
+func RemoveTool(source []byte, name string) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		return removeEntry(root, sectionTools, name)
+	})
+}
+
+// RemoveAlias removes an alias definition from source.
+func RemoveAlias(source []byte, name string) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		return removeEntry(root, sectionAliases, name)
+	})
+}
+
+// RenameTool renames a tool in source and retargets the aliases pointing at it.
+func RenameTool(source []byte, oldName string, newName string) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		if err := renameEntry(root, sectionTools, oldName, newName); err != nil {
+			return err
+		}
+		return retargetAliases(root, oldName, newName)
+	})
+}
+
+// RenameAlias renames an alias in source.
+func RenameAlias(source []byte, oldName string, newName string) ([]byte, error) {
+	return editSource(source, func(root *ast.MappingNode) error {
+		return renameEntry(root, sectionAliases, oldName, newName)
+	})
+}
+
+func editSource(source []byte, edit func(root *ast.MappingNode) error) ([]byte, error) {
+	file, err := parser.ParseBytes(source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *ast.MappingNode
+	switch node := documentBody(file).(type) {
+	case *ast.MappingNode:
+		root = node
+	case *ast.MappingValueNode:
+		root = ast.Mapping(node.GetToken(), false, node)
+		file.Docs[0].Body = root
+	default:
+		return nil, errRootNotMapping
+	}
+
+	if err = edit(root); err != nil {
+		return nil, err
+	}
+	return fileBytes(file), nil
+}
+
+// findValue returns the index of key in m, or -1.
+func findValue(m *ast.MappingNode, key string) int {
+	return slices.IndexFunc(m.Values, func(value *ast.MappingValueNode) bool {
+		return mapKeyString(value.Key) == key
+	})
+}
+
+// sectionMapping returns the block mapping under the top-level key section.
+// It returns nil when the section is missing or empty.
+func sectionMapping(root *ast.MappingNode, section string) (*ast.MappingNode, error) {
+	i := findValue(root, section)
+	if i < 0 {
+		return nil, nil
+	}
+	m, ok := root.Values[i].Value.(*ast.MappingNode)
+	if !ok {
+		return nil, nil
+	}
+	if m.IsFlowStyle {
+		if len(m.Values) == 0 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: %w", section, errFlowMappingEdit)
+	}
+	return m, nil
+}
+
+func addEntry(root *ast.MappingNode, section string, name string, value yaml.MapSlice) error {
+	m, err := sectionMapping(root, section)
+	if err != nil {
+		return err
+	}
+
+	if m == nil {
+		// Create the section, replacing an empty or null value in place.
+		node, parseErr := parseIndented(yaml.MapSlice{{Key: section, Value: yaml.MapSlice{{Key: name, Value: value}}}}, 0, defaultIndent)
+		if parseErr != nil {
+			return parseErr
+		}
+		if i := findValue(root, section); i >= 0 {
+			root.Values[i] = node
+		} else {
+			root.Values = append(root.Values, node)
+		}
+		return nil
+	}
+
+	if findValue(m, name) >= 0 {
+		return fmt.Errorf("%w: %s", ErrEntryExists, name)
+	}
+
+	column := m.Values[0].Key.GetToken().Position.Column - 1
+	indent := column - (root.Values[findValue(root, section)].Key.GetToken().Position.Column - 1)
+	if indent <= 0 {
+		indent = defaultIndent
+	}
+	node, err := parseIndented(yaml.MapSlice{{Key: name, Value: value}}, column, indent)
+	if err != nil {
+		return err
+	}
+	m.Values = append(m.Values, node)
+	return nil
+}
+
+func removeEntry(root *ast.MappingNode, section string, name string) error {
+	m, err := sectionMapping(root, section)
+	if err != nil {
+		return err
+	}
+	i := -1
+	if m != nil {
+		i = findValue(m, name)
+	}
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrEntryUnknown, name)
+	}
+
+	m.Values = slices.Delete(m.Values, i, i+1)
+	if len(m.Values) == 0 {
+		// An empty block mapping prints as nothing, so leave an explicit empty mapping behind.
+		node, parseErr := parseMappingValue(section + ": {}\n")
+		if parseErr != nil {
+			return parseErr
+		}
+		return root.Values[findValue(root, section)].Replace(node.Value)
+	}
+	return nil
+}
+
+func renameEntry(root *ast.MappingNode, section string, oldName string, newName string) error {
+	m, err := sectionMapping(root, section)
+	if err != nil {
+		return err
+	}
+	i := -1
+	if m != nil {
+		i = findValue(m, oldName)
+	}
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrEntryUnknown, oldName)
+	}
+	if findValue(m, newName) >= 0 {
+		return fmt.Errorf("%w: %s", ErrEntryExists, newName)
+	}
+
+	return replaceKey(m.Values[i], newName)
+}
+
+// retargetAliases points aliases targeting oldTool at newTool.
+func retargetAliases(root *ast.MappingNode, oldTool string, newTool string) error {
+	aliases, err := sectionMapping(root, sectionAliases)
+	if err != nil || aliases == nil {
+		return err
+	}
+
+	for _, alias := range aliases.Values {
+		fields, ok := alias.Value.(*ast.MappingNode)
+		if !ok {
+			continue
+		}
+		i := findValue(fields, "tool")
+		if i < 0 || fields.Values[i].Value.GetToken() == nil || fields.Values[i].Value.GetToken().Value != oldTool {
+			continue
+		}
+		node, parseErr := parseIndented(yaml.MapSlice{{Key: "tool", Value: newTool}}, 0, defaultIndent)
+		if parseErr != nil {
+			return parseErr
+		}
+		if err = fields.Values[i].Replace(node.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceKey renames the key of value, keeping its position so indentation is preserved.
+func replaceKey(value *ast.MappingValueNode, name string) error {
+	node, err := parseIndented(yaml.MapSlice{{Key: name, Value: nil}}, 0, defaultIndent)
+	if err != nil {
+		return err
+	}
+	node.Key.GetToken().Position = value.Key.GetToken().Position
+	value.Key = node.Key
+	return nil
+}
+
+// parseIndented marshals a single-key mapping and parses it back as a node starting at column.
+func parseIndented(value yaml.MapSlice, column int, indent int) (*ast.MappingValueNode, error) {
+	data, err := yaml.MarshalWithOptions(value, yaml.Indent(indent), yaml.IndentSequence(true))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Repeat(" ", column)
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return parseMappingValue(strings.Join(lines, ""))
+}
+
+func toolYAML(t Tool) yaml.MapSlice {
+	out := yaml.MapSlice{}
+	if t.Run != "" {
+		out = append(out, yaml.MapItem{Key: "run", Value: t.Run})
+	}
+	if len(t.Path) > 0 {
+		out = append(out, yaml.MapItem{Key: "path", Value: t.Path})
+	}
+	if args := argsYAML(t.Args); len(args) > 0 {
+		out = append(out, yaml.MapItem{Key: "args", Value: args})
+	}
+	if len(t.Env) > 0 {
+		out = append(out, yaml.MapItem{Key: "env", Value: envYAML(t.Env)})
+	}
+	if t.Description != "" {
+		out = append(out, yaml.MapItem{Key: "description", Value: t.Description})
+	}
+	if t.Instructions != "" {
+		out = append(out, yaml.MapItem{Key: "instructions", Value: t.Instructions})
+	}
+	if len(t.Tags) > 0 {
+		out = append(out, yaml.MapItem{Key: "tags", Value: t.Tags})
+	}
+	if len(t.Platforms) > 0 {
+		keys := make([]string, 0, len(t.Platforms))
+		for key := range t.Platforms {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		platforms := yaml.MapSlice{}
+		for _, key := range keys {
+			platforms = append(platforms, yaml.MapItem{Key: key, Value: toolYAML(Tool{
+				Run:  t.Platforms[key].Run,
+				Args: t.Platforms[key].Args,
+				Env:  t.Platforms[key].Env,
+			})})
+		}
+		out = append(out, yaml.MapItem{Key: "platforms", Value: platforms})
+	}
+	return out
+}
+
+func aliasYAML(a Alias) yaml.MapSlice {
+	out := yaml.MapSlice{{Key: "tool", Value: a.Tool}}
+	if args := argsYAML(a.Args); len(args) > 0 {
+		out = append(out, yaml.MapItem{Key: "args", Value: args})
+	}
+	if a.Description != "" {
+		out = append(out, yaml.MapItem{Key: "description", Value: a.Description})
+	}
+	if len(a.Tags) > 0 {
+		out = append(out, yaml.MapItem{Key: "tags", Value: a.Tags})
+	}
+	return out
+}
+
+func argsYAML(a Args) yaml.MapSlice {
+	out := yaml.MapSlice{}
+	if len(a.Prepend) > 0 {
+		out = append(out, yaml.MapItem{Key: "prepend", Value: a.Prepend})
+	}
+	if len(a.Append) > 0 {
+		out = append(out, yaml.MapItem{Key: "append", Value: a.Append})
+	}
+	return out
+}
+
+func envYAML(env map[string]string) yaml.MapSlice {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make(yaml.MapSlice, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, yaml.MapItem{Key: key, Value: env[key]})
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+const editSource = `version: 1
+# Tool area.
+directory: .private
+
+tools:
+    # Repository manager.
+    ghq:
+        run: ghq # inline
+aliases:
+    gg:
+        tool: ghq
+`
+
+func TestAddTool(t *testing.T) {
+	t.Run("appends with existing indentation", func(t *testing.T) {
+		out, err := config.AddTool([]byte(editSource), "note", config.Tool{
+			Run:  "vim",
+			Args: config.Args{Append: []string{"{{.ToolDir}}/note.md"}},
+			Env:  map[string]string{"B": "2", "A": "1"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `version: 1
+# Tool area.
+directory: .private
+
+tools:
+    # Repository manager.
+    ghq:
+        run: ghq # inline
+    note:
+        run: vim
+        args:
+            append:
+                - "{{.ToolDir}}/note.md"
+        env:
+            A: "1"
+            B: "2"
+aliases:
+    gg:
+        tool: ghq
+`, string(out))
+
+		_, err = config.Parse(out, "config.yml")
+		require.NoError(t, err)
+	})
+
+	t.Run("creates missing section", func(t *testing.T) {
+		out, err := config.AddTool([]byte("version: 1\ndirectory: .private\n"), "a", config.Tool{Run: "a"})
+		require.NoError(t, err)
+		assert.Equal(t, "version: 1\ndirectory: .private\ntools:\n  a:\n    run: a\n", string(out))
+	})
+
+	t.Run("replaces empty flow mapping", func(t *testing.T) {
+		out, err := config.AddTool([]byte("version: 1\ndirectory: .private\ntools: {}\n"), "a", config.Tool{Run: "a"})
+		require.NoError(t, err)
+		assert.Equal(t, "version: 1\ndirectory: .private\ntools:\n  a:\n    run: a\n", string(out))
+	})
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		_, err := config.AddTool([]byte(editSource), "ghq", config.Tool{Run: "ghq"})
+		require.ErrorIs(t, err, config.ErrEntryExists)
+	})
+
+	t.Run("rejects non-empty flow mapping", func(t *testing.T) {
+		_, err := config.AddTool([]byte("directory: .private\ntools: {a: {run: a}}\n"), "b", config.Tool{Run: "b"})
+		require.ErrorContains(t, err, "flow-style")
+	})
+}
+
+func TestAddAlias(t *testing.T) {
+	out, err := config.AddAlias([]byte(editSource), "gl", config.Alias{
+		Tool: "ghq",
+		Args: config.Args{Prepend: []string{"list"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `aliases:
+    gg:
+        tool: ghq
+    gl:
+        tool: ghq
+        args:
+            prepend:
+                - list
+`)
+}
+
+func TestRemoveEntries(t *testing.T) {
+	out, err := config.RemoveAlias([]byte(editSource), "gg")
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "aliases: {}\n")
+
+	out, err = config.RemoveTool(out, "ghq")
+	require.NoError(t, err)
+	cfg, err := config.Parse(out, "config.yml")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Tools)
+	assert.Contains(t, string(out), "# Tool area.\n")
+
+	_, err = config.RemoveTool([]byte(editSource), "missing")
+	require.ErrorIs(t, err, config.ErrEntryUnknown)
+}
+
+func TestRenameEntries(t *testing.T) {
+	out, err := config.RenameTool([]byte(editSource), "ghq", "repo")
+	require.NoError(t, err)
+	assert.Equal(t, `version: 1
+# Tool area.
+directory: .private
+
+tools:
+    # Repository manager.
+    repo:
+        run: ghq # inline
+aliases:
+    gg:
+        tool: repo
+`, string(out))
+
+	out, err = config.RenameAlias(out, "gg", "db.get")
+	require.NoError(t, err)
+	cfg, err := config.Parse(out, "config.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "repo", cfg.Aliases["db.get"].Tool)
+
+	_, err = config.RenameAlias([]byte(editSource), "missing", "x")
+	require.ErrorIs(t, err, config.ErrEntryUnknown)
+
+	_, err = config.RenameTool([]byte(editSource), "ghq", "ghq")
+	require.ErrorIs(t, err, config.ErrEntryExists)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GroupSeparator separates group segments in tool and alias names.
+// With a "db" group declared, a tool named "db.migrate" is run as "sidetable db migrate".
+const GroupSeparator = "."
+
+// Group holds metadata for a command group.
+// Only declared groups, and the parents of nested ones, split entry names; other dots are part of the name,
+// so tools such as "go1.22" keep working without a group.
+type Group struct {
+	Description string `yaml:"description"`
+}
+
+// JoinEntryName joins command words into an entry name.
+func JoinEntryName(words ...string) string {
+	return strings.Join(words, GroupSeparator)
+}
+
+// NormalizeEntryName accepts both "db.migrate" and "db migrate" and returns "db.migrate".
+func NormalizeEntryName(name string) string {
+	return JoinEntryName(strings.Fields(strings.ReplaceAll(name, GroupSeparator, " "))...)
+}
+
+// IsGroup reports whether name is a command group, i.e. a key of groups or the parent of one.
+func (c *Config) IsGroup(name string) bool {
+	if _, ok := c.Groups[name]; ok {
+		return true
+	}
+	for group := range c.Groups {
+		if strings.HasPrefix(group, name+GroupSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParentGroups returns the groups containing name, outermost first.
+//
+//	// groups: {db.schema: {}}
+//	ParentGroups("db.schema.dump") // ["db", "db.schema"]
+//	ParentGroups("go1.22")         // []
+func (c *Config) ParentGroups(name string) []string {
+	segments := strings.Split(name, GroupSeparator)
+	groups := make([]string, 0, len(segments)-1)
+	for i := 1; i < len(segments); i++ {
+		if prefix := JoinEntryName(segments[:i]...); c.IsGroup(prefix) {
+			groups = append(groups, prefix)
+		}
+	}
+	return groups
+}
+
+// EntryWords returns the command words used to run name.
+//
+//	// groups: {db: {}}
+//	EntryWords("db.migrate") // ["db", "migrate"]
+//	EntryWords("go1.22")     // ["go1.22"]
+func (c *Config) EntryWords(name string) []string {
+	parents := c.ParentGroups(name)
+	words := make([]string, 0, len(parents)+1)
+	start := 0
+	for _, group := range parents {
+		words = append(words, name[start:len(group)])
+		start = len(group) + len(GroupSeparator)
+	}
+	return append(words, name[start:])
+}
+
+// GroupNames returns every declared group and the parents of nested ones, sorted so that parents come first.
+func (c *Config) GroupNames() []string {
+	seen := make(map[string]bool)
+	for name := range c.Groups {
+		seen[name] = true
+		for _, group := range c.ParentGroups(name) {
+			seen[group] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func hasEmptySegment(name string) bool {
+	return slices.Contains(strings.Split(name, GroupSeparator), "")
+}
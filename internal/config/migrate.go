@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// CurrentVersion is the config format version written by this version of sidetable.
+// Configs without a version key are treated as version 0.
+const CurrentVersion = 1
+
+// Migration describes a single upgrade step between two config versions.
+type Migration struct {
+	From        int
+	To          int
+	Description string
+	// VersionOnly is set when the step changes nothing but the version key.
+	// Configs that only need such steps load as they are, so they are not warned about.
+	VersionOnly bool
+}
+
+type migrationStep struct {
+	Migration
+
+	apply func(root ast.Node) (ast.Node, error)
+}
+
+// migrationSteps are applied in order to upgrade a config document.
+// Each step must upgrade the document from Migration.From to Migration.To.
+var migrationSteps = []migrationStep{ //nolint:gochecknoglobals // static migration table
+	{
+		Migration: Migration{
+			From:        0,
+			To:          1,
+			Description: "add top-level version key",
+			VersionOnly: true,
+		},
+		apply: func(root ast.Node) (ast.Node, error) {
+			return root, nil
+		},
+	},
+}
+
+var errRootNotMapping = errors.New("config root must be a mapping")
+
+// MigrateSource upgrades a config document to CurrentVersion.
+// Comments and key order are preserved.
+// It returns the source unchanged when no migration is needed.
+func MigrateSource(source []byte) ([]byte, []Migration, error) {
+	file, err := parser.ParseBytes(source, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	applied, err := migrateFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(applied) == 0 {
+		return source, nil, nil
+	}
+
+	return fileBytes(file), applied, nil
+}
+
+// fileBytes prints file, ending it with a newline.
+func fileBytes(file *ast.File) []byte {
+	out := file.String()
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out += "\n"
+	}
+	return []byte(out)
+}
+
+// migrateFile upgrades the first document of file in place and returns the applied steps.
+func migrateFile(file *ast.File) ([]Migration, error) {
+	if len(file.Docs) == 0 {
+		return nil, nil
+	}
+	doc := file.Docs[0]
+	root := documentBody(file)
+	if root == nil {
+		return nil, nil
+	}
+
+	version, ok := readVersion(root)
+	if !ok || version < 0 || version >= CurrentVersion {
+		// Invalid and newer versions are reported by validation.
+		return nil, nil
+	}
+
+	applied := make([]Migration, 0, CurrentVersion-version)
+	for _, step := range migrationSteps {
+		if step.From < version {
+			continue
+		}
+
+		var err error
+		root, err = step.apply(root)
+		if err != nil {
+			return nil, fmt.Errorf("migrate config from version %d to %d: %w", step.From, step.To, err)
+		}
+		applied = append(applied, step.Migration)
+	}
+
+	root, err := setVersion(root, CurrentVersion)
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = root
+
+	return applied, nil
+}
+
+// documentBody returns the root node of the first document, or nil when the document is empty.
+func documentBody(file *ast.File) ast.Node {
+	if file == nil || len(file.Docs) == 0 {
+		return nil
+	}
+	body := file.Docs[0].Body
+	if body == nil || body.Type() == ast.CommentType {
+		return nil
+	}
+	return body
+}
+
+// readVersion returns the version declared in root.
+// A missing version key is reported as version 0.
+func readVersion(root ast.Node) (int, bool) {
+	m, ok := root.(mapRanger)
+	if !ok {
+		return 0, false
+	}
+
+	iter := m.MapRange()
+	for iter.Next() {
+		if mapKeyString(iter.Key()) != "version" {
+			continue
+		}
+		tk := iter.Value().GetToken()
+		if tk == nil {
+			return 0, false
+		}
+		version, err := strconv.Atoi(tk.Value)
+		if err != nil {
+			return 0, false
+		}
+		return version, true
+	}
+
+	return 0, true
+}
+
+// setVersion updates the version key of root, inserting it as the first key when missing.
+func setVersion(root ast.Node, version int) (ast.Node, error) {
+	entry, err := parseMappingValue(fmt.Sprintf("version: %d\n", version))
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := root.(type) {
+	case *ast.MappingNode:
+		for _, value := range node.Values {
+			if mapKeyString(value.Key) == "version" {
+				return root, value.Replace(entry.Value)
+			}
+		}
+		node.Values = append([]*ast.MappingValueNode{entry}, node.Values...)
+		return node, nil
+	case *ast.MappingValueNode:
+		if mapKeyString(node.Key) == "version" {
+			return root, node.Replace(entry.Value)
+		}
+		return ast.Mapping(node.GetToken(), false, entry, node), nil
+	default:
+		return nil, errRootNotMapping
+	}
+}
+
+// parseMappingValue parses a single "key: value" line into a mapping entry.
+func parseMappingValue(src string) (*ast.MappingValueNode, error) {
+	file, err := parser.ParseBytes([]byte(src), parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := documentBody(file).(type) {
+	case *ast.MappingNode:
+		return node.Values[0], nil
+	case *ast.MappingValueNode:
+		return node, nil
+	default:
+		return nil, fmt.Errorf("unexpected node for %q", src)
+	}
+}
+
+// outdatedVersionWarning returns the warning for a config upgraded in memory by applied,
+// or an empty string when the upgrade only set the version.
+func outdatedVersionWarning(applied []Migration) string {
+	if !slices.ContainsFunc(applied, func(m Migration) bool { return !m.VersionOnly }) {
+		return ""
+	}
+	return fmt.Sprintf(
+		"config version %d is outdated and was upgraded in memory to version %d; run `sidetable migrate` to update the file",
+		applied[0].From,
+		CurrentVersion,
+	)
+}
@@ -0,0 +1,35 @@
+//nolint:testpackage // Need access to the package-private migration table.
+package config
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_WarnsOnlyAboutMigrationsThatChangeTheConfig(t *testing.T) {
+	orig := migrationSteps
+	t.Cleanup(func() { migrationSteps = orig })
+
+	source := []byte("directory: .private\ntools: {}\n")
+
+	cfg, err := Parse(source, "config.yml")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Warnings)
+
+	migrationSteps = []migrationStep{
+		{
+			Migration: Migration{From: 0, To: 1, Description: "rename a key"},
+			apply: func(root ast.Node) (ast.Node, error) {
+				return root, nil
+			},
+		},
+	}
+	cfg, err = Parse(source, "config.yml")
+	require.NoError(t, err)
+	require.Len(t, cfg.Warnings, 1)
+	assert.Contains(t, cfg.Warnings[0], "config version 0 is outdated")
+	assert.Contains(t, cfg.Warnings[0], "sidetable migrate")
+}
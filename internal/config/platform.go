@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"runtime"
+	"strings"
+)
+
+// ErrToolUnavailable is returned when a tool has no definition for the current platform.
+var ErrToolUnavailable = errors.New("tool is not available on this platform")
+
+// Platform identifies an operating system and architecture pair using Go's GOOS and GOARCH values.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// CurrentPlatform returns the platform sidetable is running on.
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// PlatformOverride overrides tool fields on matching platforms.
+type PlatformOverride struct {
+	Run  string            `yaml:"run"`
+	Args Args              `yaml:"args"`
+	Env  map[string]string `yaml:"env"`
+}
+
+// knownGOOS and knownGOARCH list the values accepted in platform keys.
+//
+//nolint:gochecknoglobals // static lookup tables
+var (
+	knownGOOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"illumos": true, "ios": true, "js": true, "linux": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true,
+	}
+	knownGOARCH = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true,
+		"mips": true, "mips64": true, "mips64le": true, "mipsle": true, "ppc64": true,
+		"ppc64le": true, "riscv64": true, "s390x": true, "wasm": true,
+	}
+)
+
+// isKnownPlatformKey reports whether key is a GOOS, a GOARCH, or a "GOOS/GOARCH" pair.
+func isKnownPlatformKey(key string) bool {
+	if goos, goarch, ok := strings.Cut(key, "/"); ok {
+		return knownGOOS[goos] && knownGOARCH[goarch]
+	}
+	return knownGOOS[key] || knownGOARCH[key]
+}
+
+// ForPlatform returns the tool with matching platform overrides applied.
+// Overrides are applied from least to most specific: GOARCH, then GOOS, then "GOOS/GOARCH".
+// The returned tool has no platform overrides left.
+func (t Tool) ForPlatform(p Platform) Tool {
+	resolved := t
+	resolved.Platforms = nil
+	resolved.Env = maps.Clone(t.Env)
+
+	for _, key := range []string{p.Arch, p.OS, p.String()} {
+		override, ok := t.Platforms[key]
+		if !ok {
+			continue
+		}
+		if override.Run != "" {
+			resolved.Run = override.Run
+		}
+		if override.Args.Prepend != nil {
+			resolved.Args.Prepend = override.Args.Prepend
+		}
+		if override.Args.Append != nil {
+			resolved.Args.Append = override.Args.Append
+		}
+		if len(override.Env) > 0 {
+			if resolved.Env == nil {
+				resolved.Env = make(map[string]string, len(override.Env))
+			}
+			maps.Copy(resolved.Env, override.Env)
+		}
+	}
+
+	return resolved
+}
+
+// AvailableOn reports whether the tool can run on p.
+// A tool without a top-level run is only available on platforms whose overrides set one.
+func (t Tool) AvailableOn(p Platform) bool {
+	return t.ForPlatform(p).Run != ""
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -12,23 +13,52 @@ import (
 	"github.com/sushichan044/sidetable/internal/builtin"
 )
 
+// maxExitCode is the largest exit status a process can report.
+const maxExitCode = 255
+
 const (
+	msgVersionMustNotBeNegative = "version must not be negative"
+
 	msgDirectoryRequired       = "directory is required"
 	msgDirectoryMustBeRelative = "directory must be relative"
 
 	msgToolRunRequired            = "tool run is required"
 	msgToolRunMustNotContainSpace = "tool run must not contain spaces"
-	msgToolConflictsWithBuiltin   = "tool conflicts with builtin command"
+	msgToolConflictsWithBuiltin   = `tool conflicts with builtin command; rename it with "sidetable tool rename"`
+	msgEntryNameInvalid           = "name must not contain / or \\ and must not be . or .."
+	msgPlatformUnknown            = "platform must be a GOOS, a GOARCH or GOOS/GOARCH"
+
+	msgNameSegmentEmpty          = "name must not contain empty group segments"
+	msgEntryConflictsWithGroup   = "entry name conflicts with group"
+	msgGroupUnused               = "group has no tools or aliases"
+	msgGroupConflictsWithBuiltin = "group conflicts with builtin command"
+
+	msgTagInvalid     = "tag must not be empty or contain spaces"
+	msgTimeoutInvalid = "timeout must be a positive duration such as 30s or 5m"
+
+	msgLockInvalid              = "lock must be exclusive, shared or none"
+	msgStopSignalInvalid        = "stop_signal must be one of SIGTERM, SIGINT, SIGHUP, SIGQUIT, SIGKILL, SIGUSR1 or SIGUSR2"
+	msgStopSignalWithoutService = "stop_signal requires service: true"
+
+	msgRetryAttemptsInvalid = "retry attempts must not be negative"
+	msgRetryDelayInvalid    = "retry delay must be a duration such as 500ms or 2s"
+	msgRetryBackoffInvalid  = "retry backoff must be at least 1"
+	msgRetryExitCodeInvalid = "retry exit code must be between 1 and 255"
 
 	msgAliasNameRequired         = "alias name is required"
 	msgAliasMustNotContainSpaces = "alias must not contain spaces"
 	msgAliasToolRequired         = "alias tool is required"
 	msgAliasConflictsWithTool    = "alias conflicts with tool name"
-	msgAliasConflictsWithBuiltin = "alias conflicts with builtin command"
+	msgAliasConflictsWithBuiltin = `alias conflicts with builtin command; rename it with "sidetable alias rename"`
 	msgAliasTargetUnknown        = "alias tool not found"
 )
 
 var (
+	msgVersionUnsupported = fmt.Sprintf(
+		"version is newer than supported version %d; please upgrade sidetable",
+		CurrentVersion,
+	)
+
 	argsSchema = z.Struct(z.Shape{
 		"prepend": z.Slice(z.String()),
 		"append":  z.Slice(z.String()),
@@ -38,19 +68,75 @@ var (
 		z.String(),
 	)
 
-	toolSchema = z.Struct(z.Shape{
-		"run": z.String().
-			Required(z.Message(msgToolRunRequired)).
+	tagsSchema = z.Slice(z.String().
+			Required(z.Message(msgTagInvalid)).
+			TestFunc(func(val *string, _ z.Ctx) bool {
+			return !strings.ContainsAny(*val, " \t\n\r")
+		}, z.Message(msgTagInvalid)))
+
+	timeoutSchema = z.String().
+			TestFunc(func(val *string, _ z.Ctx) bool {
+			_, err := ParseTimeout(*val)
+			return err == nil
+		}, z.Message(msgTimeoutInvalid))
+
+	retrySchema = z.Struct(z.Shape{
+		"attempts": z.Int().GTE(0, z.Message(msgRetryAttemptsInvalid)),
+		"delay": z.String().
+			TestFunc(func(val *string, _ z.Ctx) bool {
+				_, err := ParseRetryDelay(*val)
+				return err == nil
+			}, z.Message(msgRetryDelayInvalid)),
+		"backoff": z.Float64().
+			TestFunc(func(val *float64, _ z.Ctx) bool {
+				return *val == 0 || *val >= 1
+			}, z.Message(msgRetryBackoffInvalid)),
+		// Exit codes are checked in validateCrossRules because zog does not test zero values.
+		"onExitCodes": z.Slice(z.Int()),
+	})
+
+	lockSchema = z.String().OneOf([]string{"exclusive", "shared", "none"}, z.Message(msgLockInvalid))
+
+	runSchema = z.String().
 			TestFunc(func(val *string, _ z.Ctx) bool {
-				return !strings.ContainsAny(*val, " \t\n\r")
-			}, z.Message(msgToolRunMustNotContainSpace)),
+			return !strings.ContainsAny(*val, " \t\n\r")
+		}, z.Message(msgToolRunMustNotContainSpace))
+
+	platformSchema = z.Struct(z.Shape{
+		"run":  runSchema,
+		"args": argsSchema,
+		"env":  envSchema,
+	})
+	platformKeySchema = z.String().
+				TestFunc(func(val *string, _ z.Ctx) bool {
+			return isKnownPlatformKey(*val)
+		}, z.Message(msgPlatformUnknown))
+
+	// Required run is checked in validateCrossRules because platforms may provide it instead.
+	toolSchema = z.Struct(z.Shape{
+		"run":          runSchema,
+		"path":         z.Slice(z.String()),
 		"args":         argsSchema,
 		"env":          envSchema,
 		"description":  z.String(),
 		"instructions": z.String(),
+		"tags":         tagsSchema,
+		"timeout":      timeoutSchema,
+		"exec":         z.Bool(),
+		"retry":        retrySchema,
+		"lock":         lockSchema,
+		"service":      z.Bool(),
+		"stopSignal":   z.String(),
+		"platforms": z.EXPERIMENTAL_MAP[string, PlatformOverride](
+			platformKeySchema,
+			platformSchema,
+		),
 	})
 	toolNameSchema = z.String().
 			TestFunc(func(val *string, _ z.Ctx) bool {
+			return IsValidEntryName(*val)
+		}, z.Message(msgEntryNameInvalid)).
+		TestFunc(func(val *string, _ z.Ctx) bool {
 			return !builtin.IsReservedName(*val)
 		}, z.Message(msgToolConflictsWithBuiltin))
 
@@ -58,17 +144,30 @@ var (
 		"tool":        z.String().Required(z.Message(msgAliasToolRequired)),
 		"args":        argsSchema,
 		"description": z.String(),
+		"tags":        tagsSchema,
+		"timeout":     timeoutSchema,
+		"lock":        lockSchema,
 	})
 	aliasNameSchema = z.String().
 			Required(z.Message(msgAliasNameRequired)).
 			TestFunc(func(val *string, _ z.Ctx) bool {
 			return !strings.ContainsAny(*val, " \t\n\r")
 		}, z.Message(msgAliasMustNotContainSpaces)).
+		TestFunc(func(val *string, _ z.Ctx) bool {
+			return IsValidEntryName(*val)
+		}, z.Message(msgEntryNameInvalid)).
 		TestFunc(func(val *string, _ z.Ctx) bool {
 			return !builtin.IsReservedName(*val)
 		}, z.Message(msgAliasConflictsWithBuiltin))
 
 	configSchema = z.Struct(z.Shape{
+		"version": z.Int().
+			TestFunc(func(val *int, _ z.Ctx) bool {
+				return *val >= 0
+			}, z.Message(msgVersionMustNotBeNegative)).
+			TestFunc(func(val *int, _ z.Ctx) bool {
+				return *val <= CurrentVersion
+			}, z.Message(msgVersionUnsupported)),
 		"directory": z.String().
 			Required(z.Message(msgDirectoryRequired)).
 			TestFunc(func(val *string, _ z.Ctx) bool {
@@ -77,6 +176,8 @@ var (
 			TestFunc(func(val *string, _ z.Ctx) bool {
 				return !filepath.IsAbs(*val)
 			}, z.Message(msgDirectoryMustBeRelative)),
+		"path":       z.Slice(z.String()),
+		"gitExclude": z.Bool(),
 		"tools": z.EXPERIMENTAL_MAP[string, Tool](
 			toolNameSchema,
 			toolSchema,
@@ -85,6 +186,12 @@ var (
 			aliasNameSchema,
 			aliasSchema,
 		),
+		"groups": z.EXPERIMENTAL_MAP[string, Group](
+			z.String(),
+			z.Struct(z.Shape{
+				"description": z.String(),
+			}),
+		),
 	})
 )
 
@@ -96,7 +203,12 @@ func (c *Config) validateWithSchema() z.ZogIssueList {
 	issues := make(z.ZogIssueList, 0)
 	issues = append(issues, configSchema.Validate(c)...)
 	issues = append(issues, validateCrossRules(c)...)
+	sortIssues(issues)
+
+	return issues
+}
 
+func sortIssues(issues z.ZogIssueList) {
 	sort.SliceStable(issues, func(i, j int) bool {
 		lhsPath := issues[i].PathString()
 		rhsPath := issues[j].PathString()
@@ -105,13 +217,41 @@ func (c *Config) validateWithSchema() z.ZogIssueList {
 		}
 		return issues[i].Message < issues[j].Message
 	})
-
-	return issues
 }
 
 func validateCrossRules(config *Config) z.ZogIssueList {
 	issues := make(z.ZogIssueList, 0)
 
+	for _, toolName := range config.ToolNames() {
+		tool := config.Tools[toolName]
+		for i, code := range tool.Retry.OnExitCodes {
+			if code < 1 || code > maxExitCode {
+				codePath := []string{"tools", bracketKey(toolName), "retry", "on_exit_codes", fmt.Sprintf("[%d]", i)}
+				issues = append(issues, newCustomIssue(codePath, msgRetryExitCodeInvalid))
+			}
+		}
+		signalPath := []string{"tools", bracketKey(toolName), "stop_signal"}
+		switch {
+		case !IsStopSignal(tool.StopSignal):
+			issues = append(issues, newCustomIssue(signalPath, msgStopSignalInvalid))
+		case tool.StopSignal != "" && !tool.Service:
+			issues = append(issues, newCustomIssue(signalPath, msgStopSignalWithoutService))
+		}
+		if tool.Run != "" {
+			continue
+		}
+		hasPlatformRun := false
+		for _, override := range tool.Platforms {
+			if override.Run != "" {
+				hasPlatformRun = true
+				break
+			}
+		}
+		if !hasPlatformRun {
+			issues = append(issues, newCustomIssue([]string{"tools", bracketKey(toolName), "run"}, msgToolRunRequired))
+		}
+	}
+
 	aliasNames := make([]string, 0, len(config.Aliases))
 	for aliasName := range config.Aliases {
 		aliasNames = append(aliasNames, aliasName)
@@ -135,46 +275,80 @@ func validateCrossRules(config *Config) z.ZogIssueList {
 		}
 	}
 
-	return issues
-}
-
-func bracketKey(key string) string {
-	return `["` + key + `"]`
-}
+	issues = append(issues, validateGroups(config, aliasNames)...)
 
-func newCustomIssue(path []string, message string) *z.ZogIssue {
-	return (&z.ZogIssue{}).
-		SetCode(zconst.IssueCodeCustom).
-		SetPath(path).
-		SetMessage(message)
+	return issues
 }
 
-type validationIssueError struct {
-	issue *z.ZogIssue
-}
+// validateGroups checks that entry names form a consistent command tree.
+func validateGroups(config *Config, aliasNames []string) z.ZogIssueList {
+	issues := make(z.ZogIssueList, 0)
 
-func newValidationIssueError(issue *z.ZogIssue) error {
-	return validationIssueError{issue: issue}
-}
+	groups := make(map[string]bool)
+	for _, group := range config.GroupNames() {
+		groups[group] = true
+	}
 
-func (e validationIssueError) Error() string {
-	if e.issue == nil {
-		return "invalid config"
+	checkName := func(section string, name string) {
+		path := []string{section, bracketKey(name)}
+		if len(config.ParentGroups(name)) > 0 && hasEmptySegment(name) {
+			issues = append(issues, newCustomIssue(path, msgNameSegmentEmpty))
+		}
+		if groups[name] {
+			issues = append(issues, newCustomIssue(path, msgEntryConflictsWithGroup))
+		}
+	}
+	for _, name := range config.ToolNames() {
+		checkName("tools", name)
+	}
+	for _, name := range aliasNames {
+		checkName("aliases", name)
 	}
 
-	msg := e.issue.Message
-	if msg == "" {
-		msg = e.issue.Error()
+	groupNames := make([]string, 0, len(config.Groups))
+	for name := range config.Groups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+	for _, name := range groupNames {
+		path := []string{"groups", bracketKey(name)}
+		if hasEmptySegment(name) {
+			issues = append(issues, newCustomIssue(path, msgNameSegmentEmpty))
+		}
+		if builtin.IsReservedName(strings.Split(name, GroupSeparator)[0]) {
+			issues = append(issues, newCustomIssue(path, msgGroupConflictsWithBuiltin))
+		}
+		if !groupHasEntries(config, name) {
+			issues = append(issues, newCustomIssue(path, msgGroupUnused))
+		}
 	}
 
-	path := e.issue.PathString()
-	if path == "" {
-		return msg
+	return issues
+}
+
+// groupHasEntries reports whether a tool or alias is named below group.
+func groupHasEntries(config *Config, group string) bool {
+	prefix := group + GroupSeparator
+	for name := range config.Tools {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
 	}
+	for name := range config.Aliases {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
 
-	return path + ": " + msg
+func bracketKey(key string) string {
+	return `["` + key + `"]`
 }
 
-func (e validationIssueError) Unwrap() error {
-	return e.issue
+func newCustomIssue(path []string, message string) *z.ZogIssue {
+	return (&z.ZogIssue{}).
+		SetCode(zconst.IssueCodeCustom).
+		SetPath(path).
+		SetMessage(message)
 }
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Oudwins/zog/zconst"
+	"github.com/goccy/go-yaml/ast"
+
+	z "github.com/Oudwins/zog"
+)
+
+const issueCodeUnknownField zconst.ZogIssueCode = "unknown_field"
+
+// maxSuggestionDistance is the largest edit distance offered as a "did you mean" suggestion.
+const maxSuggestionDistance = 2
+
+// unknownFieldNote follows the unknown field warnings to explain how to act on them.
+const unknownFieldNote = "unknown fields are ignored for now but will be rejected by a future release; " +
+	"check the config with `sidetable validate`, or set SIDETABLE_LENIENT_CONFIG=1 to silence these warnings"
+
+// unknownFieldWarnings formats the unknown field issues in src as warnings.
+func unknownFieldWarnings(src *sourceFile, file string) []string {
+	issues := unknownFieldIssues(src.root)
+	if len(issues) == 0 {
+		return nil
+	}
+	sortIssues(issues)
+
+	warnings := make([]string, 0, len(issues)+1)
+	for _, issue := range issues {
+		warnings = append(warnings, newIssueDiagnosticError(issue, file, src).Error())
+	}
+	return append(warnings, unknownFieldNote)
+}
+
+// unknownFieldIssues reports mapping keys in root that do not correspond to a field of Config.
+func unknownFieldIssues(root ast.Node) z.ZogIssueList {
+	if root == nil {
+		return nil
+	}
+	return collectUnknownFields(root, reflect.TypeFor[Config](), nil)
+}
+
+func collectUnknownFields(node ast.Node, typ reflect.Type, path []string) z.ZogIssueList {
+	m, ok := node.(mapRanger)
+	if !ok {
+		// Type mismatches are reported by the decoder.
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		fields := yamlFields(typ)
+		issues := make(z.ZogIssueList, 0)
+		iter := m.MapRange()
+		for iter.Next() {
+			key := mapKeyString(iter.Key())
+			if key == "<<" {
+				continue
+			}
+			fieldType, known := fields[key]
+			if !known {
+				issues = append(issues, newUnknownFieldIssue(appendPath(path, key), key, fields))
+				continue
+			}
+			issues = append(issues, collectUnknownFields(iter.Value(), fieldType, appendPath(path, key))...)
+		}
+		return issues
+	case reflect.Map:
+		issues := make(z.ZogIssueList, 0)
+		iter := m.MapRange()
+		for iter.Next() {
+			entryPath := appendPath(path, bracketKey(mapKeyString(iter.Key())))
+			issues = append(issues, collectUnknownFields(iter.Value(), typ.Elem(), entryPath)...)
+		}
+		return issues
+	default:
+		return nil
+	}
+}
+
+// yamlFields returns the decodable yaml keys of a struct type mapped to their field types.
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, typ.NumField())
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func mapKeyString(key ast.MapKeyNode) string {
+	if key == nil || key.GetToken() == nil {
+		return ""
+	}
+	return key.GetToken().Value
+}
+
+func appendPath(path []string, segment string) []string {
+	next := make([]string, 0, len(path)+1)
+	next = append(next, path...)
+	return append(next, segment)
+}
+
+func newUnknownFieldIssue(path []string, key string, fields map[string]reflect.Type) *z.ZogIssue {
+	msg := fmt.Sprintf("unknown field %q", key)
+	if suggestion := suggestName(key, fields); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return newCustomIssue(path, msg).SetCode(issueCodeUnknownField)
+}
+
+// suggestName returns the known name closest to key, or an empty string if none is close enough.
+func suggestName(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best := ""
+	bestDistance := maxSuggestionDistance + 1
+	for _, name := range names {
+		if d := levenshtein(strings.ToLower(key), name); d < bestDistance {
+			best = name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,53 @@
+// Package filelock provides advisory locks on files that exclude other processes.
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	filePerm = 0o644
+	dirPerm  = 0o755
+)
+
+// Lock is an exclusive lock held on a lock file.
+type Lock struct {
+	file *os.File
+}
+
+// Acquire blocks until it holds an exclusive lock on the file at path, creating the file and its directory if needed.
+// The lock file must not be the file it guards when that file is replaced by renaming, since a lock is tied to the
+// file that was opened.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, filePerm)
+	if err != nil {
+		return nil, err
+	}
+	if err = lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	return l.file.Close()
+}
+
+// TryLock takes a lock on f without blocking and reports whether it was acquired.
+// Shared locks may be held by several processes at once, but not together with an exclusive lock.
+// The lock is released when f is closed.
+func TryLock(f *os.File, shared bool) (bool, error) {
+	return tryLockFile(f, shared)
+}
+
+// KeepOpenOnExec keeps f, and the lock on it, open in a program that replaces the current process.
+// It does nothing on Windows, where the process is never replaced.
+func KeepOpenOnExec(f *os.File) error {
+	return keepOpenOnExec(f)
+}
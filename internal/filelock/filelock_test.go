@@ -0,0 +1,31 @@
+package filelock_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/filelock"
+)
+
+func TestAcquireExcludesOtherLocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "file.lock")
+
+	lock, err := filelock.Acquire(path)
+	require.NoError(t, err)
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	require.NoError(t, err)
+	defer f.Close()
+
+	acquired, err := filelock.TryLock(f, true)
+	require.NoError(t, err)
+	require.False(t, acquired, "a shared lock must wait for the exclusive lock")
+
+	require.NoError(t, lock.Release())
+	acquired, err = filelock.TryLock(f, false)
+	require.NoError(t, err)
+	require.True(t, acquired)
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package filelock
+
+import (
+	"errors"
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is the byte range locked in the lock file. It lies far beyond any content,
+// because Windows also blocks reads of locked ranges.
+const lockOffset = math.MaxUint32
+
+// lockFile takes an exclusive LockFileEx lock on f, waiting for other holders.
+func lockFile(f *os.File) error {
+	overlapped := &windows.Overlapped{Offset: lockOffset}
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, overlapped)
+}
+
+// tryLockFile takes a LockFileEx lock on f without blocking and reports whether it was acquired.
+func tryLockFile(f *os.File, shared bool) (bool, error) {
+	flags := uint32(windows.LOCKFILE_FAIL_IMMEDIATELY)
+	if !shared {
+		flags |= windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	overlapped := &windows.Overlapped{Offset: lockOffset}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// keepOpenOnExec does nothing on Windows, where the process is never replaced.
+func keepOpenOnExec(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package fileutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// maxSymlinkHops bounds how many symbolic links resolveTarget follows before giving up.
+const maxSymlinkHops = 40
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers never observe a partially written file.
+// When path is a symbolic link, the file it points to is replaced and the link is kept.
+// The mode of an existing file is preserved; perm is used for new files.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	path, err := resolveTarget(path)
+	if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// resolveTarget returns the file path refers to after following symbolic links.
+// A link to a file that does not exist yet resolves to where the file would be created.
+func resolveTarget(path string) (string, error) {
+	for range maxSymlinkHops {
+		info, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		link, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(path), link)
+		}
+		path = link
+	}
+	return "", &os.PathError{Op: "write", Path: path, Err: errors.New("too many levels of symbolic links")}
+}
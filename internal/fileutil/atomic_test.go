@@ -0,0 +1,84 @@
+package fileutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/fileutil"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yml")
+
+	require.NoError(t, fileutil.WriteFileAtomic(path, []byte("a"), 0o600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(data))
+
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Chmod(path, 0o640))
+	}
+	require.NoError(t, fileutil.WriteFileAtomic(path, []byte("b"), 0o600))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(data))
+
+	if runtime.GOOS != "windows" {
+		info, statErr := os.Stat(path)
+		require.NoError(t, statErr)
+		require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must be cleaned up")
+}
+
+func TestWriteFileAtomicKeepsSymlink(t *testing.T) {
+	dotfiles := t.TempDir()
+	target := filepath.Join(dotfiles, "sidetable", "config.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("a"), 0o600))
+
+	dir := t.TempDir()
+	link := filepath.Join(dir, "config.yml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	require.NoError(t, fileutil.WriteFileAtomic(link, []byte("b"), 0o600))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink, "the link must be kept")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Equal(t, "b", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary files must be created next to the target")
+}
+
+func TestWriteFileAtomicDanglingSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.yml")
+	link := filepath.Join(dir, "config.yml")
+	if err := os.Symlink("real.yml", link); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+
+	require.NoError(t, fileutil.WriteFileAtomic(link, []byte("a"), 0o600))
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	require.Equal(t, "a", string(data))
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+}
@@ -0,0 +1,278 @@
+// Package gitrepo locates git repositories and manages their info/exclude file.
+//
+// Repository discovery reads the .git entries directly, so it works without a git binary.
+// Linked worktrees and submodules, whose .git is a file pointing elsewhere, are supported.
+package gitrepo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository is returned when no git repository encloses a directory.
+var ErrNotRepository = errors.New("not a git repository")
+
+const (
+	gitDirPrefix = "gitdir:"
+	excludePerm  = 0o644
+	infoDirPerm  = 0o755
+)
+
+// Repo describes a git working tree.
+type Repo struct {
+	// WorkTree is the top-level directory of the working tree.
+	WorkTree string
+	// GitDir is the git directory of the working tree.
+	// For linked worktrees and submodules, it is the directory the .git file points to.
+	GitDir string
+	// CommonDir holds data shared by all worktrees, including info/exclude.
+	// It equals GitDir unless the working tree is a linked worktree.
+	CommonDir string
+}
+
+// Find returns the repository whose working tree contains dir, searching dir and its parents.
+// The innermost repository wins, so a directory inside a submodule belongs to the submodule.
+func Find(dir string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		repo, findErr := open(dir)
+		if findErr == nil {
+			return repo, nil
+		}
+		if !errors.Is(findErr, fs.ErrNotExist) {
+			return nil, findErr
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+// open reads the .git entry of workTree. It returns an fs.ErrNotExist error when there is none.
+func open(workTree string) (*Repo, error) {
+	dotGit := filepath.Join(workTree, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return nil, err
+	}
+
+	gitDir := dotGit
+	if !info.IsDir() {
+		gitDir, err = readGitFile(dotGit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commonDir, err := readCommonDir(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Repo{WorkTree: workTree, GitDir: gitDir, CommonDir: commonDir}, nil
+}
+
+// readGitFile resolves a "gitdir: <path>" file as written for linked worktrees and submodules.
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	target, ok := strings.CutPrefix(strings.TrimSpace(line), gitDirPrefix)
+	if !ok {
+		return "", fmt.Errorf("invalid git file %s", path)
+	}
+	target = filepath.FromSlash(strings.TrimSpace(target))
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return filepath.Clean(target), nil
+}
+
+// readCommonDir returns the directory named by gitDir/commondir, or gitDir when the file does not exist.
+func readCommonDir(gitDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return gitDir, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	commonDir := filepath.FromSlash(strings.TrimSpace(string(data)))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir), nil
+}
+
+// ExcludeFile returns the path of the repository's info/exclude file.
+func (r *Repo) ExcludeFile() string {
+	return filepath.Join(r.CommonDir, "info", "exclude")
+}
+
+// Pattern returns an exclude pattern matching exactly the directory dir,
+// anchored at the top of the working tree. dir must be inside the working tree.
+// Characters with a special meaning in exclude patterns are escaped.
+func (r *Repo) Pattern(dir string) (string, error) {
+	rel, err := r.rel(dir)
+	if err != nil {
+		return "", err
+	}
+	return "/" + patternEscaper.Replace(rel) + "/", nil
+}
+
+//nolint:gochecknoglobals // static replacer
+var patternEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"?", `\?`,
+	"[", `\[`,
+	"!", `\!`,
+	"#", `\#`,
+)
+
+// rel returns dir relative to the working tree in slash form.
+func (r *Repo) rel(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(r.WorkTree, dir)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not inside the working tree %s", dir, r.WorkTree)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// IsExcluded reports whether pattern is listed in the info/exclude file.
+// A pattern without its trailing slash counts as listed too.
+func (r *Repo) IsExcluded(pattern string) (bool, error) {
+	data, err := os.ReadFile(r.ExcludeFile())
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	bare := strings.TrimSuffix(pattern, "/")
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == pattern || line == bare {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// Exclude appends pattern to the info/exclude file unless it is already listed.
+// It reports whether the file was changed.
+func (r *Repo) Exclude(pattern, comment string) (bool, error) {
+	excluded, err := r.IsExcluded(pattern)
+	if err != nil || excluded {
+		return false, err
+	}
+
+	path := r.ExcludeFile()
+	if err = os.MkdirAll(filepath.Dir(path), infoDirPerm); err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	if comment != "" {
+		buf.WriteString("# " + comment + "\n")
+	}
+	buf.WriteString(pattern + "\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, excludePerm)
+	if err != nil {
+		return false, err
+	}
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// IsIgnored asks git whether dir is ignored by any rule: .gitignore files, info/exclude or core.excludesFile.
+// It requires the git binary.
+func (r *Repo) IsIgnored(ctx context.Context, dir string) (bool, error) {
+	rel, err := r.rel(dir)
+	if err != nil {
+		return false, err
+	}
+
+	// --no-index also reports paths that are ignored but tracked.
+	_, err = r.git(ctx, "check-ignore", "--quiet", "--no-index", "--", rel+"/")
+	if err == nil {
+		return true, nil
+	}
+	if exitErr := new(exec.ExitError); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+// TrackedFiles asks git for the files under dir that are in the index, relative to the working tree.
+// It requires the git binary.
+func (r *Repo) TrackedFiles(ctx context.Context, dir string) ([]string, error) {
+	rel, err := r.rel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The literal magic keeps characters such as "*" in the path from being expanded as a glob.
+	out, err := r.git(ctx, "ls-files", "-z", "--", ":(literal)"+rel+"/")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for file := range strings.SplitSeq(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (r *Repo) git(ctx context.Context, args ...string) ([]byte, error) {
+	// #nosec G204 -- fixed git subcommands; only paths are variable.
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", r.WorkTree}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr := new(exec.ExitError); errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return out, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return out, err
+	}
+	return out, nil
+}
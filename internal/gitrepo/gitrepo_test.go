@@ -0,0 +1,155 @@
+package gitrepo_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/gitrepo"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	mainGit := filepath.Join(root, "main", ".git")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "main", "sub", "deep"), 0o755))
+	require.NoError(t, os.MkdirAll(mainGit, 0o755))
+
+	// Submodule: .git file pointing into the superproject's modules directory.
+	subGit := filepath.Join(mainGit, "modules", "sub")
+	require.NoError(t, os.MkdirAll(subGit, 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "main", "sub", ".git"),
+		[]byte("gitdir: ../.git/modules/sub\n"),
+		0o600,
+	))
+
+	// Linked worktree: .git file pointing into worktrees/, with commondir back to the main git dir.
+	wtGit := filepath.Join(mainGit, "worktrees", "wt")
+	require.NoError(t, os.MkdirAll(wtGit, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(wtGit, "commondir"), []byte("../..\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "wt"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "wt", ".git"), []byte("gitdir: "+wtGit+"\n"), 0o600))
+
+	repo, err := gitrepo.Find(filepath.Join(root, "main"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "main"), repo.WorkTree)
+	require.Equal(t, filepath.Join(mainGit, "info", "exclude"), repo.ExcludeFile())
+
+	repo, err = gitrepo.Find(filepath.Join(root, "main", "sub", "deep"))
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "main", "sub"), repo.WorkTree)
+	require.Equal(t, subGit, repo.GitDir)
+	require.Equal(t, filepath.Join(subGit, "info", "exclude"), repo.ExcludeFile())
+
+	repo, err = gitrepo.Find(filepath.Join(root, "wt"))
+	require.NoError(t, err)
+	require.Equal(t, wtGit, repo.GitDir)
+	require.Equal(t, mainGit, repo.CommonDir)
+	require.Equal(t, filepath.Join(mainGit, "info", "exclude"), repo.ExcludeFile())
+
+	_, err = gitrepo.Find(root)
+	require.ErrorIs(t, err, gitrepo.ErrNotRepository)
+}
+
+func TestExclude(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "info"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("*.log"), 0o600))
+
+	repo, err := gitrepo.Find(root)
+	require.NoError(t, err)
+
+	pattern, err := repo.Pattern(filepath.Join(root, "pkg", ".private"))
+	require.NoError(t, err)
+	require.Equal(t, "/pkg/.private/", pattern)
+
+	_, err = repo.Pattern(filepath.Dir(root))
+	require.Error(t, err)
+
+	changed, err := repo.Exclude(pattern, "added by test")
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	changed, err = repo.Exclude(pattern, "added by test")
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	data, err := os.ReadFile(repo.ExcludeFile())
+	require.NoError(t, err)
+	require.Equal(t, "*.log\n# added by test\n/pkg/.private/\n", string(data))
+
+	excluded, err := repo.IsExcluded("/other/")
+	require.NoError(t, err)
+	require.False(t, excluded)
+}
+
+func TestIsIgnoredAndTrackedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "--quiet")
+	area := filepath.Join(root, ".private")
+	require.NoError(t, os.MkdirAll(area, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(area, "note.md"), []byte("x"), 0o600))
+	git("add", ".private/note.md")
+
+	repo, err := gitrepo.Find(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	ignored, err := repo.IsIgnored(ctx, area)
+	require.NoError(t, err)
+	require.False(t, ignored)
+
+	tracked, err := repo.TrackedFiles(ctx, area)
+	require.NoError(t, err)
+	require.Equal(t, []string{".private/note.md"}, tracked)
+
+	_, err = repo.Exclude("/.private/", "")
+	require.NoError(t, err)
+	ignored, err = repo.IsIgnored(ctx, area)
+	require.NoError(t, err)
+	require.True(t, ignored)
+}
+
+func TestPatternEscapesSpecialCharacters(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	repo, err := gitrepo.Find(root)
+	require.NoError(t, err)
+
+	area := filepath.Join(root, "#tools", "a*b?[c]!")
+	pattern, err := repo.Pattern(area)
+	require.NoError(t, err)
+	require.Equal(t, `/\#tools/a\*b\?\[c]\!/`, pattern)
+
+	if _, err = exec.LookPath("git"); err != nil {
+		return
+	}
+	out, err := exec.Command("git", "-C", root, "init", "--quiet").CombinedOutput()
+	require.NoError(t, err, string(out))
+	require.NoError(t, os.MkdirAll(area, 0o755))
+	sibling := filepath.Join(root, "#tools", "aXbY[c]!")
+	require.NoError(t, os.MkdirAll(sibling, 0o755))
+	_, err = repo.Exclude(pattern, "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ignored, err := repo.IsIgnored(ctx, area)
+	require.NoError(t, err)
+	require.True(t, ignored)
+	ignored, err = repo.IsIgnored(ctx, sibling)
+	require.NoError(t, err)
+	require.False(t, ignored, "the pattern must only match the tool area itself")
+}
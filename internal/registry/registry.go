@@ -0,0 +1,148 @@
+// Package registry records the workspaces sidetable has been used in.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/filelock"
+	"github.com/sushichan044/sidetable/internal/fileutil"
+	"github.com/sushichan044/sidetable/internal/xdg"
+)
+
+const (
+	stateDirEnv = "SIDETABLE_STATE_DIR"
+	fileName    = "workspaces.json"
+	// lockSuffix names the lock file guarding updates of the registry. The registry itself cannot be locked,
+	// because saving replaces it.
+	lockSuffix = ".lock"
+
+	filePerm = 0o600
+	dirPerm  = 0o755
+)
+
+// Workspace is a recorded workspace.
+type Workspace struct {
+	Root string `json:"root"`
+	// Area is the tool area of the workspace when it was last used.
+	Area     string    `json:"area"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Registry is the list of recorded workspaces.
+type Registry struct {
+	Workspaces []Workspace `json:"workspaces"`
+}
+
+// StateDir returns the sidetable state directory: SIDETABLE_STATE_DIR, or "sidetable" under XDG_STATE_HOME.
+func StateDir() (string, error) {
+	if dir := os.Getenv(stateDirEnv); dir != "" {
+		return dir, nil
+	}
+	stateHome, err := xdg.StateHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateHome, "sidetable"), nil
+}
+
+// Path returns the registry path in StateDir.
+func Path() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the registry at path. A missing file is an empty registry.
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Registry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r Registry
+	if err = json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Save writes the registry to path, most recently used workspace first.
+func (r *Registry) Save(path string) error {
+	sort.SliceStable(r.Workspaces, func(i, j int) bool {
+		return r.Workspaces[i].LastUsed.After(r.Workspaces[j].LastUsed)
+	})
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(path, append(data, '\n'), filePerm)
+}
+
+// Touch records that the workspace at root was used at now.
+func (r *Registry) Touch(root, area string, now time.Time) {
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Root == root {
+			r.Workspaces[i].Area = area
+			r.Workspaces[i].LastUsed = now
+			return
+		}
+	}
+	r.Workspaces = append(r.Workspaces, Workspace{Root: root, Area: area, LastUsed: now})
+}
+
+// Forget removes the workspace at root and reports whether it was recorded.
+func (r *Registry) Forget(root string) bool {
+	for i := range r.Workspaces {
+		if r.Workspaces[i].Root == root {
+			r.Workspaces = append(r.Workspaces[:i], r.Workspaces[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Update loads the registry at path, applies fn and saves it when fn reports a change.
+// Updates hold a lock from load to save, so concurrent processes do not lose each other's changes.
+func Update(path string, fn func(r *Registry) bool) error {
+	lock, err := filelock.Acquire(path + lockSuffix)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	r, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if !fn(r) {
+		return nil
+	}
+	return r.Save(path)
+}
+
+// Record touches root in the registry at Path.
+func Record(root, area string, now time.Time) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return Update(path, func(r *Registry) bool {
+		r.Touch(root, area, now)
+		return true
+	})
+}
@@ -0,0 +1,69 @@
+package registry_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	stateDir := t.TempDir()
+	t.Setenv("SIDETABLE_STATE_DIR", stateDir)
+
+	path, err := registry.Path()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(stateDir, "workspaces.json"), path)
+
+	r, err := registry.Load(path)
+	require.NoError(t, err)
+	require.Empty(t, r.Workspaces)
+
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, registry.Record("/a", "/a/.private", first))
+	require.NoError(t, registry.Record("/b", "/b/.private", first.Add(time.Hour)))
+	require.NoError(t, registry.Record("/a", "/a/.area", first.Add(2*time.Hour)))
+
+	r, err = registry.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, []registry.Workspace{
+		{Root: "/a", Area: "/a/.area", LastUsed: first.Add(2 * time.Hour)},
+		{Root: "/b", Area: "/b/.private", LastUsed: first.Add(time.Hour)},
+	}, r.Workspaces)
+
+	require.True(t, r.Forget("/a"))
+	require.False(t, r.Forget("/missing"))
+	require.NoError(t, r.Save(path))
+
+	r, err = registry.Load(path)
+	require.NoError(t, err)
+	require.Len(t, r.Workspaces, 1)
+	require.Equal(t, "/b", r.Workspaces[0].Root)
+}
+
+func TestRecordConcurrently(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+
+	const workspaces = 20
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := range workspaces {
+		wg.Go(func() {
+			root := fmt.Sprintf("/w%d", i)
+			assert.NoError(t, registry.Record(root, root+"/.private", now))
+		})
+	}
+	wg.Wait()
+
+	path, err := registry.Path()
+	require.NoError(t, err)
+	r, err := registry.Load(path)
+	require.NoError(t, err)
+	require.Len(t, r.Workspaces, workspaces, "concurrent records must not be lost")
+}
@@ -0,0 +1,212 @@
+// Package trust records the project-level configs the user has allowed sidetable to load.
+//
+// A project-level config comes with the repository it is in, so a cloned repository could otherwise
+// define the tools sidetable runs and exposes over MCP without the user noticing.
+package trust
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/filelock"
+	"github.com/sushichan044/sidetable/internal/fileutil"
+	"github.com/sushichan044/sidetable/internal/registry"
+)
+
+const (
+	fileName   = "trusted.json"
+	lockSuffix = ".lock"
+	filePerm   = 0o600
+	dirPerm    = 0o755
+)
+
+// Config is a trusted config file.
+type Config struct {
+	Path string `json:"path"`
+	// SHA256 is the hex digest of the config contents when it was trusted.
+	SHA256    string    `json:"sha256"`
+	TrustedAt time.Time `json:"trusted_at"`
+}
+
+// Status is the trust status of a config file.
+type Status int
+
+const (
+	// Untrusted means the config has never been trusted, or trust in it was revoked.
+	Untrusted Status = iota
+	// Trusted means the config is trusted and unchanged since.
+	Trusted
+	// Changed means the config was trusted, but its contents have changed since.
+	Changed
+)
+
+type list struct {
+	Configs []Config `json:"configs"`
+}
+
+// Path returns the trust list path in the sidetable state directory.
+func Path() (string, error) {
+	dir, err := registry.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// IsTrusted reports whether the config at path has been trusted and is unchanged since.
+func IsTrusted(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	status, err := Check(path, content)
+	return status == Trusted, err
+}
+
+// Check returns the trust status of the config at path with the given contents.
+// Callers pass the contents they are about to load, so the file cannot change between the check and the load.
+func Check(path string, content []byte) (Status, error) {
+	key, err := normalize(path)
+	if err != nil {
+		return Untrusted, err
+	}
+	configs, err := List()
+	if err != nil {
+		return Untrusted, err
+	}
+	i := slices.IndexFunc(configs, func(c Config) bool { return c.Path == key })
+	switch {
+	case i < 0:
+		return Untrusted, nil
+	case configs[i].SHA256 != digest(content):
+		return Changed, nil
+	default:
+		return Trusted, nil
+	}
+}
+
+// List returns the trusted configs.
+func List() ([]Config, error) {
+	listPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	l, err := load(listPath)
+	if err != nil {
+		return nil, err
+	}
+	return l.Configs, nil
+}
+
+// Add trusts the current contents of the config at path and reports whether they were not trusted before.
+func Add(path string, now time.Time) (bool, error) {
+	key, err := normalize(path)
+	if err != nil {
+		return false, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	sum := digest(content)
+	return update(func(l *list) bool {
+		i := slices.IndexFunc(l.Configs, func(c Config) bool { return c.Path == key })
+		if i < 0 {
+			l.Configs = append(l.Configs, Config{Path: key, SHA256: sum, TrustedAt: now})
+			return true
+		}
+		if l.Configs[i].SHA256 == sum {
+			return false
+		}
+		l.Configs[i].SHA256 = sum
+		l.Configs[i].TrustedAt = now
+		return true
+	})
+}
+
+// Remove revokes trust in the config at path and reports whether it was trusted.
+func Remove(path string) (bool, error) {
+	key, err := normalize(path)
+	if err != nil {
+		return false, err
+	}
+	return update(func(l *list) bool {
+		before := len(l.Configs)
+		l.Configs = slices.DeleteFunc(l.Configs, func(c Config) bool { return c.Path == key })
+		return len(l.Configs) != before
+	})
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalize returns the absolute path of the config with symbolic links resolved,
+// so that trust follows the file rather than the way it was reached.
+func normalize(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		return abs, nil
+	}
+	return resolved, err
+}
+
+// update applies fn to the trust list under a lock and saves it when fn reports a change.
+func update(fn func(l *list) bool) (bool, error) {
+	listPath, err := Path()
+	if err != nil {
+		return false, err
+	}
+	lock, err := filelock.Acquire(listPath + lockSuffix)
+	if err != nil {
+		return false, err
+	}
+	defer lock.Release()
+
+	l, err := load(listPath)
+	if err != nil {
+		return false, err
+	}
+	if !fn(l) {
+		return false, nil
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	if err = os.MkdirAll(filepath.Dir(listPath), dirPerm); err != nil {
+		return false, err
+	}
+	return true, fileutil.WriteFileAtomic(listPath, append(data, '\n'), filePerm)
+}
+
+func load(path string) (*list, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &list{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var l list
+	if err = json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,83 @@
+package trust_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+func TestTrust(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".sidetable.yml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\n"), 0o600))
+
+	trusted, err := trust.IsTrusted(path)
+	require.NoError(t, err)
+	require.False(t, trusted)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	added, err := trust.Add(path, now)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = trust.Add(path, now)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	trusted, err = trust.IsTrusted(path)
+	require.NoError(t, err)
+	require.True(t, trusted)
+
+	link := filepath.Join(t.TempDir(), ".sidetable.yml")
+	if err = os.Symlink(path, link); err == nil {
+		trusted, err = trust.IsTrusted(link)
+		require.NoError(t, err)
+		require.True(t, trusted, "trust follows the file a link points to")
+	}
+
+	removed, err := trust.Remove(path)
+	require.NoError(t, err)
+	require.True(t, removed)
+	removed, err = trust.Remove(path)
+	require.NoError(t, err)
+	require.False(t, removed)
+
+	configs, err := trust.List()
+	require.NoError(t, err)
+	require.Empty(t, configs)
+}
+
+func TestTrustFollowsContents(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), ".sidetable.yml")
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\n"), 0o600))
+
+	_, err := trust.Add(path, time.Now())
+	require.NoError(t, err)
+
+	changed := []byte("version: 1\ntools:\n  x:\n    run: curl\n")
+	status, err := trust.Check(path, changed)
+	require.NoError(t, err)
+	require.Equal(t, trust.Changed, status)
+
+	require.NoError(t, os.WriteFile(path, changed, 0o600))
+	trusted, err := trust.IsTrusted(path)
+	require.NoError(t, err)
+	require.False(t, trusted, "changed contents must be reviewed again")
+
+	added, err := trust.Add(path, time.Now())
+	require.NoError(t, err)
+	require.True(t, added)
+	status, err = trust.Check(path, changed)
+	require.NoError(t, err)
+	require.Equal(t, trust.Trusted, status)
+
+	configs, err := trust.List()
+	require.NoError(t, err)
+	require.Len(t, configs, 1, "trusting again updates the existing record")
+}
@@ -0,0 +1,33 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+func StateHome() (string, error) {
+	stateHome, err := getStateHome()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Clean(stateHome), nil
+}
+
+func getStateHome() (string, error) {
+	if runtime.GOOS == "windows" {
+		// %LocalAppData%, which is not roamed between machines.
+		return os.UserCacheDir()
+	}
+
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" {
+		return stateHome, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
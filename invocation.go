@@ -4,33 +4,106 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sushichan044/sidetable/internal/config"
 )
 
 // Invocation is a fully resolved process invocation.
 type Invocation struct {
+	// ToolName is the tool being invoked.
+	ToolName string
+	// AliasName is the alias used to invoke the tool, if any.
+	AliasName string
+
 	Program string
-	Args    []string
-	Env     []string
+	// Path is the absolute path Program was resolved to.
+	// It is empty until the program has been looked up.
+	Path string
+	// SearchPath lists directories searched for Program before PATH.
+	SearchPath []string
+	Args       []string
+	// ArgSources describes where each element of Args came from.
+	ArgSources []ArgSource
+	Env        []string
+	// Timeout limits how long the process may run. Zero means no timeout.
+	Timeout time.Duration
+	// Exec reports whether the tool asks to replace the calling process when run from the command line.
+	Exec bool
+	// Retry describes how Run runs the process again when it fails.
+	Retry RetryPolicy
+	// Lock is taken on the tool directory while the process runs. Empty means LockNone.
+	Lock LockMode
+	// Service reports whether the tool is a long-running service started with StartService.
+	Service bool
 }
 
+// ArgSource describes where an invocation argument came from.
+type ArgSource string
+
+const (
+	ArgSourceAliasPrepend ArgSource = "alias prepend"
+	ArgSourceToolPrepend  ArgSource = "tool prepend"
+	ArgSourceUser         ArgSource = "user"
+	ArgSourceToolAppend   ArgSource = "tool append"
+	ArgSourceAliasAppend  ArgSource = "alias append"
+)
+
 // InvokeOptions configures process execution.
 type InvokeOptions struct {
 	Stdin  io.Reader
 	Stdout io.Writer
 	Stderr io.Writer
+	// Origin is recorded in the invocation history. It defaults to OriginAPI.
+	Origin Origin
+	// Timeout overrides the timeout of the invocation when positive.
+	Timeout time.Duration
+	// ForwardSignals passes SIGINT, SIGTERM and SIGHUP received by the current process on to the tool
+	// while it runs, instead of letting them terminate the current process.
+	// On Windows, Ctrl-C is ignored by the current process and left to the tool.
+	ForwardSignals bool
+	// Exec controls whether Run may replace the current process with the tool. It defaults to ExecNever.
+	Exec ExecMode
+	// LockNoWait fails with a LockError right away when the lock of the tool is held by another process.
+	LockNoWait bool
+	// LockTimeout limits how long Run waits for the lock of the tool when positive.
+	// Without it, Run waits until ctx is done.
+	LockTimeout time.Duration
 }
 
+// ExecMode controls whether Run replaces the current process with the tool instead of starting a child process.
+//
+// The process is only replaced on Unix, when no timeout applies and the tool uses the standard streams
+// of the current process. Otherwise the tool runs as a child process. Once replaced, Run does not return.
+type ExecMode int
+
+const (
+	// ExecNever always runs the tool as a child process.
+	ExecNever ExecMode = iota
+	// ExecConfigured replaces the current process when the tool sets exec.
+	ExecConfigured
+	// ExecAlways replaces the current process regardless of the tool setting.
+	ExecAlways
+)
+
 // InvocationError represents a process that exited with non-zero status.
 type InvocationError struct {
+	// Code is the exit status, or 128 plus the signal number when the process was killed by a signal.
 	Code int
-	Err  error
+	// Signal is the signal that killed the process, or nil when it exited normally.
+	Signal os.Signal
+	// Attempts is the number of times the process was run, including retries.
+	Attempts int
+	Err      error
 }
 
 func (e *InvocationError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("invocation failed with exit code %d after %d attempts: %v", e.Code, e.Attempts, e.Err)
+	}
 	return fmt.Sprintf("invocation failed with exit code %d: %v", e.Code, e.Err)
 }
 
@@ -38,6 +111,32 @@ func (e *InvocationError) Unwrap() error {
 	return e.Err
 }
 
+// TimeoutError represents a process that was stopped because it exceeded its timeout.
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("invocation timed out after %s", e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// AsTimeoutError extracts TimeoutError from err.
+func AsTimeoutError(err error) (*TimeoutError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if timeoutErr := new(TimeoutError); errors.As(err, &timeoutErr) {
+		return timeoutErr, true
+	}
+
+	return nil, false
+}
+
 // AsInvocationError extracts InvocationError from err.
 //
 //	if invErr, ok := sidetable.AsInvocationError(err); ok {
@@ -57,6 +156,7 @@ func AsInvocationError(err error) (*InvocationError, bool) {
 var (
 	errRunTemplateEmpty    = errors.New("run template resolved to empty")
 	errRunTemplateHasSpace = errors.New("run template contains spaces")
+	errSearchPathRelative  = errors.New("path entry must be absolute")
 )
 
 func resolveInvocation(
@@ -73,7 +173,7 @@ func resolveInvocation(
 
 	ctx := templateContext{
 		WorkspaceRoot: workspaceRoot,
-		ToolDir:       filepath.Join(workspaceRoot, cfg.Directory, resolved.ToolName),
+		ToolDir:       toolDir(workspaceRoot, cfg, resolved.ToolName),
 		ConfigDir:     filepath.Dir(cfg.FilePath),
 	}
 
@@ -88,7 +188,12 @@ func resolveInvocation(
 		return Invocation{}, errRunTemplateHasSpace
 	}
 
-	resolvedArgs, err := buildArgsWithAlias(resolved.Tool.Args, resolved.AliasArgs, userArgs, ctx)
+	searchPath, err := buildSearchPath(resolved.Tool.Path, cfg.Path, ctx)
+	if err != nil {
+		return Invocation{}, err
+	}
+
+	resolvedArgs, argSources, err := buildArgsWithAlias(resolved.Tool.Args, resolved.AliasArgs, userArgs, ctx)
 	if err != nil {
 		return Invocation{}, err
 	}
@@ -99,51 +204,105 @@ func resolveInvocation(
 	}
 	env := envSliceFromMap(envMap)
 
+	retryDelay, err := config.ParseRetryDelay(resolved.Tool.Retry.Delay)
+	if err != nil {
+		return Invocation{}, err
+	}
+
 	return Invocation{
-		Program: program,
-		Args:    resolvedArgs,
-		Env:     env,
+		ToolName:   resolved.ToolName,
+		AliasName:  resolved.AliasName,
+		Program:    program,
+		SearchPath: searchPath,
+		Args:       resolvedArgs,
+		ArgSources: argSources,
+		Env:        env,
+		Timeout:    resolved.Timeout,
+		Exec:       resolved.Tool.Exec,
+		Retry: RetryPolicy{
+			Attempts:    resolved.Tool.Retry.Attempts,
+			Delay:       retryDelay,
+			Backoff:     resolved.Tool.Retry.Backoff,
+			OnExitCodes: resolved.Tool.Retry.OnExitCodes,
+		},
+		Lock:    LockMode(resolved.Lock),
+		Service: resolved.Tool.Service,
 	}, nil
 }
 
+// buildSearchPath evaluates tool and top-level path entries, in that order.
+func buildSearchPath(toolPath []string, configPath []string, ctx templateContext) ([]string, error) {
+	toolDirs, err := buildArgList(toolPath, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tool path: %w", err)
+	}
+	configDirs, err := buildArgList(configPath, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+
+	dirs := make([]string, 0, len(toolDirs)+len(configDirs))
+	dirs = append(dirs, toolDirs...)
+	dirs = append(dirs, configDirs...)
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			return nil, fmt.Errorf("%w: %s", errSearchPathRelative, dir)
+		}
+	}
+	return dirs, nil
+}
+
 func buildArgsWithAlias(
 	toolArgs config.Args,
 	aliasArgs *config.Args,
 	userArgs []string,
 	ctx templateContext,
-) ([]string, error) {
+) ([]string, []ArgSource, error) {
 	var aliasPrepend, aliasAppend []string
 	var err error
 
 	if aliasArgs != nil {
 		aliasPrepend, err = buildArgList(aliasArgs.Prepend, ctx)
 		if err != nil {
-			return nil, fmt.Errorf("alias prepend: %w", err)
+			return nil, nil, fmt.Errorf("alias prepend: %w", err)
 		}
 		aliasAppend, err = buildArgList(aliasArgs.Append, ctx)
 		if err != nil {
-			return nil, fmt.Errorf("alias append: %w", err)
+			return nil, nil, fmt.Errorf("alias append: %w", err)
 		}
 	}
 
 	toolPrepend, err := buildArgList(toolArgs.Prepend, ctx)
 	if err != nil {
-		return nil, fmt.Errorf("tool prepend: %w", err)
+		return nil, nil, fmt.Errorf("tool prepend: %w", err)
 	}
 	toolAppend, err := buildArgList(toolArgs.Append, ctx)
 	if err != nil {
-		return nil, fmt.Errorf("tool append: %w", err)
+		return nil, nil, fmt.Errorf("tool append: %w", err)
+	}
+
+	parts := []struct {
+		args   []string
+		source ArgSource
+	}{
+		{aliasPrepend, ArgSourceAliasPrepend},
+		{toolPrepend, ArgSourceToolPrepend},
+		{userArgs, ArgSourceUser},
+		{toolAppend, ArgSourceToolAppend},
+		{aliasAppend, ArgSourceAliasAppend},
 	}
 
 	totalLen := len(aliasPrepend) + len(toolPrepend) + len(userArgs) + len(toolAppend) + len(aliasAppend)
 	result := make([]string, 0, totalLen)
-	result = append(result, aliasPrepend...)
-	result = append(result, toolPrepend...)
-	result = append(result, userArgs...)
-	result = append(result, toolAppend...)
-	result = append(result, aliasAppend...)
+	sources := make([]ArgSource, 0, totalLen)
+	for _, part := range parts {
+		result = append(result, part.args...)
+		for range part.args {
+			sources = append(sources, part.source)
+		}
+	}
 
-	return result, nil
+	return result, sources, nil
 }
 
 func buildArgList(args []string, ctx templateContext) ([]string, error) {
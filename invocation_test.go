@@ -2,8 +2,11 @@
 package sidetable
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -129,3 +132,79 @@ func TestResolveInvocationTemplateErrors(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestResolveInvocationArgSources(t *testing.T) {
+	cfg := &config.Config{
+		Directory: ".private",
+		Tools: map[string]config.Tool{
+			"tool": {
+				Run:  "tool",
+				Args: config.Args{Prepend: []string{"tp"}, Append: []string{"ta"}},
+			},
+		},
+		Aliases: map[string]config.Alias{
+			"t": {
+				Tool: "tool",
+				Args: config.Args{Prepend: []string{"ap"}, Append: []string{"aa"}},
+			},
+		},
+	}
+
+	inv, err := resolveInvocation(cfg, "t", []string{"u1", "u2"}, t.TempDir(), []string{})
+	require.NoError(t, err)
+	require.Equal(t, "tool", inv.ToolName)
+	require.Equal(t, "t", inv.AliasName)
+	require.Equal(t, []string{"ap", "tp", "u1", "u2", "ta", "aa"}, inv.Args)
+	require.Equal(t, []ArgSource{
+		ArgSourceAliasPrepend,
+		ArgSourceToolPrepend,
+		ArgSourceUser,
+		ArgSourceUser,
+		ArgSourceToolAppend,
+		ArgSourceAliasAppend,
+	}, inv.ArgSources)
+}
+
+func TestCanReplaceProcess(t *testing.T) {
+	if !execSupported {
+		t.Skip("the process cannot be replaced on this platform")
+	}
+
+	tests := []struct {
+		name string
+		inv  Invocation
+		opts InvokeOptions
+		want bool
+	}{
+		{name: "never", inv: Invocation{Exec: true}, opts: InvokeOptions{}, want: false},
+		{name: "configured", inv: Invocation{Exec: true}, opts: InvokeOptions{Exec: ExecConfigured}, want: true},
+		{name: "not configured", inv: Invocation{}, opts: InvokeOptions{Exec: ExecConfigured}, want: false},
+		{name: "always", inv: Invocation{}, opts: InvokeOptions{Exec: ExecAlways}, want: true},
+		{
+			name: "standard streams",
+			inv:  Invocation{},
+			opts: InvokeOptions{Exec: ExecAlways, Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr},
+			want: true,
+		},
+		{
+			name: "configured timeout",
+			inv:  Invocation{Exec: true, Timeout: time.Second},
+			opts: InvokeOptions{Exec: ExecConfigured},
+			want: false,
+		},
+		{name: "timeout override", inv: Invocation{}, opts: InvokeOptions{Exec: ExecAlways, Timeout: time.Second}, want: false},
+		{
+			name: "retry",
+			inv:  Invocation{Exec: true, Retry: RetryPolicy{Attempts: 2}},
+			opts: InvokeOptions{Exec: ExecConfigured},
+			want: false,
+		},
+		{name: "captured output", inv: Invocation{}, opts: InvokeOptions{Exec: ExecAlways, Stdout: io.Discard}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, canReplaceProcess(tt.inv, tt.opts))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package sidetable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/filelock"
+)
+
+const (
+	// ToolLockFileName is the lock file created in the directory of tools with a lock.
+	ToolLockFileName = ".sidetable.lock"
+
+	lockFilePerm = 0o644
+	// lockPollInterval is how often a held lock is tried again while waiting for it.
+	lockPollInterval = 100 * time.Millisecond
+	// lockHolderMaxSize is more than enough for a PID and a newline.
+	lockHolderMaxSize = 32
+)
+
+// LockMode controls whether a tool may run while another run of it is in progress.
+type LockMode string
+
+const (
+	// LockNone lets runs of the tool overlap freely.
+	LockNone LockMode = "none"
+	// LockExclusive lets only one run of the tool proceed at a time.
+	LockExclusive LockMode = "exclusive"
+	// LockShared lets runs with a shared lock overlap, but not with a run holding an exclusive lock.
+	LockShared LockMode = "shared"
+)
+
+// LockError represents a tool lock that is held by another process.
+type LockError struct {
+	Tool string
+	// Path is the lock file.
+	Path string
+	// PID is the process that most recently acquired the lock, or 0 when unknown.
+	PID int
+}
+
+func (e *LockError) Error() string {
+	return fmt.Sprintf("tool %q is locked by %s (%s)", e.Tool, e.holder(), e.Path)
+}
+
+func (e *LockError) holder() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("PID %d", e.PID)
+	}
+	return "another process"
+}
+
+// AsLockError extracts LockError from err.
+func AsLockError(err error) (*LockError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if lockErr := new(LockError); errors.As(err, &lockErr) {
+		return lockErr, true
+	}
+	return nil, false
+}
+
+// toolLock is a lock held on a tool directory.
+type toolLock struct {
+	file *os.File
+}
+
+// Release releases the lock.
+func (l *toolLock) Release() {
+	if l != nil {
+		l.file.Close()
+	}
+}
+
+// KeepOnExec keeps the lock held by the program that replaces the current process, until it exits.
+func (l *toolLock) KeepOnExec() error {
+	if l == nil {
+		return nil
+	}
+	return filelock.KeepOpenOnExec(l.file)
+}
+
+// lockTool acquires the lock configured for inv in the tool directory, creating the directory if needed.
+// It returns a nil lock when the tool has no lock.
+//
+// A held lock is waited for until ctx is done or opts.LockTimeout expires, or not at all with opts.LockNoWait.
+// The first time it waits, a line naming the holder is printed to stderr.
+func (w *Workspace) lockTool(ctx context.Context, inv Invocation, opts InvokeOptions) (*toolLock, error) {
+	if inv.Lock == "" || inv.Lock == LockNone {
+		return nil, nil
+	}
+
+	dir := toolDir(w.rootDir, w.config, inv.ToolName)
+	if err := os.MkdirAll(dir, toolDirPerm); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, ToolLockFileName)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, lockFilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.LockTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.LockTimeout)
+		defer cancel()
+	}
+
+	waiting := false
+	for {
+		acquired, lockErr := filelock.TryLock(f, inv.Lock == LockShared)
+		if lockErr != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, lockErr)
+		}
+		if acquired {
+			writeLockHolder(f)
+			return &toolLock{file: f}, nil
+		}
+
+		heldErr := &LockError{Tool: inv.ToolName, Path: path, PID: readLockHolder(f)}
+		if opts.LockNoWait {
+			f.Close()
+			return nil, heldErr
+		}
+		if !waiting {
+			waiting = true
+			fmt.Fprintf(stderrOf(opts), "sidetable: waiting for the lock on %q held by %s\n", inv.ToolName, heldErr.holder())
+		}
+		if sleepContext(ctx, lockPollInterval) != nil {
+			f.Close()
+			return nil, heldErr
+		}
+	}
+}
+
+// lockHolder reports the process holding the lock in dir, like lockTool with LockNoWait would.
+// It returns 0 and false when the lock is free or dir has no lock file.
+// Unlike lockTool it never creates the lock file or records a holder,
+// so probing a directory does not change its modification time.
+func lockHolder(dir string) (int, bool, error) {
+	path := filepath.Join(dir, ToolLockFileName)
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+
+	acquired, err := filelock.TryLock(f, false)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if acquired {
+		return 0, false, nil
+	}
+	return readLockHolder(f), true, nil
+}
+
+// writeLockHolder records the current process as the holder of the lock in f.
+// Holders of a shared lock overwrite each other, so the recorded PID is the most recent holder.
+func writeLockHolder(f *os.File) {
+	if err := f.Truncate(0); err != nil {
+		return
+	}
+	_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+}
+
+// readLockHolder returns the PID recorded in f, or 0 when none is recorded.
+func readLockHolder(f *os.File) int {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, lockHolderMaxSize))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
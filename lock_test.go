@@ -0,0 +1,125 @@
+//go:build !windows
+
+package sidetable_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+// holdLock runs name until the test ends, and returns once the run has started and holds its lock.
+func holdLock(t *testing.T, ws *sidetable.Workspace, name string) {
+	t.Helper()
+
+	marker := filepath.Join(t.TempDir(), "started")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = ws.Run(ctx, name, []string{marker}, sidetable.InvokeOptions{Stderr: &bytes.Buffer{}})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func lockWorkspace(t *testing.T) *sidetable.Workspace {
+	t.Helper()
+
+	// The marker is passed as $0, so a run proves it holds its lock by creating it.
+	tool := shellTool(`touch "$0"; sleep 10; :`)
+	tool.Lock = "shared"
+	return setupTestWorkspace(t, map[string]config.Tool{
+		"db":   tool,
+		"free": shellTool(`touch "$0"; sleep 10; :`),
+	}, map[string]config.Alias{
+		"db-write": {Tool: "db", Lock: "exclusive"},
+	})
+}
+
+func TestWorkspaceRunExclusiveLock(t *testing.T) {
+	ws := lockWorkspace(t)
+	holdLock(t, ws, "db-write")
+
+	err := ws.Run(context.Background(), "db", []string{os.DevNull}, sidetable.InvokeOptions{LockNoWait: true})
+	lockErr, ok := sidetable.AsLockError(err)
+	require.True(t, ok, "expected lock error, got %v", err)
+	require.Equal(t, "db", lockErr.Tool)
+	require.Equal(t, os.Getpid(), lockErr.PID)
+	toolDir, err := ws.ToolDir("db")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(toolDir, sidetable.ToolLockFileName), lockErr.Path)
+	require.Contains(t, lockErr.Error(), "locked by PID")
+
+	var stderr bytes.Buffer
+	start := time.Now()
+	err = ws.Run(context.Background(), "db-write", []string{os.DevNull}, sidetable.InvokeOptions{
+		Stderr:      &stderr,
+		LockTimeout: 200 * time.Millisecond,
+	})
+	_, ok = sidetable.AsLockError(err)
+	require.True(t, ok, "expected lock error, got %v", err)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Contains(t, stderr.String(), `sidetable: waiting for the lock on "db" held by PID`)
+}
+
+func TestWorkspaceRunSharedLock(t *testing.T) {
+	ws := lockWorkspace(t)
+	holdLock(t, ws, "db")
+	holdLock(t, ws, "db")
+
+	err := ws.Run(context.Background(), "db-write", []string{os.DevNull}, sidetable.InvokeOptions{LockNoWait: true})
+	_, ok := sidetable.AsLockError(err)
+	require.True(t, ok, "expected lock error, got %v", err)
+}
+
+func TestWorkspaceRunWithoutLock(t *testing.T) {
+	ws := lockWorkspace(t)
+	holdLock(t, ws, "free")
+	holdLock(t, ws, "free")
+
+	toolDir, err := ws.ToolDir("free")
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(toolDir, sidetable.ToolLockFileName))
+}
+
+func TestWorkspaceRunWaitsForLock(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"job": {
+			Run:  "sh",
+			Args: config.Args{Prepend: []string{"-c", `touch "$0"; sleep 0.3`}},
+			Lock: "exclusive",
+		},
+	}, nil)
+
+	marker := filepath.Join(t.TempDir(), "started")
+	first := make(chan error, 1)
+	go func() {
+		first <- ws.Run(context.Background(), "job", []string{marker}, sidetable.InvokeOptions{})
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var stderr bytes.Buffer
+	second := filepath.Join(t.TempDir(), "second")
+	require.NoError(t, ws.Run(context.Background(), "job", []string{second}, sidetable.InvokeOptions{Stderr: &stderr}))
+	require.NoError(t, <-first)
+	require.Contains(t, stderr.String(), "waiting for the lock")
+}
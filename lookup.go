@@ -0,0 +1,70 @@
+package sidetable
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ProgramNotFoundError is returned when the program of an invocation cannot be found.
+type ProgramNotFoundError struct {
+	Program string
+	// Searched lists the locations checked, in order.
+	Searched []string
+}
+
+func (e *ProgramNotFoundError) Error() string {
+	if len(e.Searched) == 0 {
+		return fmt.Sprintf("program %q not found: no search path", e.Program)
+	}
+	return fmt.Sprintf("program %q not found in: %s", e.Program, strings.Join(e.Searched, string(filepath.ListSeparator)))
+}
+
+// AsProgramNotFoundError extracts ProgramNotFoundError from err.
+func AsProgramNotFoundError(err error) (*ProgramNotFoundError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	if notFound := new(ProgramNotFoundError); errors.As(err, &notFound) {
+		return notFound, true
+	}
+
+	return nil, false
+}
+
+// lookupProgram resolves inv.Program to an absolute path and stores it in inv.Path.
+//
+// A program containing a path separator is used as is.
+// Otherwise inv.SearchPath is searched first, followed by PATH from inv.Env.
+// Relative PATH entries are skipped, matching exec.LookPath's refusal to run programs from the current directory.
+func lookupProgram(inv *Invocation) error {
+	if strings.ContainsRune(inv.Program, '/') || strings.ContainsRune(inv.Program, filepath.Separator) {
+		path, err := exec.LookPath(inv.Program)
+		if err != nil {
+			return &ProgramNotFoundError{Program: inv.Program, Searched: []string{inv.Program}}
+		}
+		inv.Path, err = filepath.Abs(path)
+		return err
+	}
+
+	dirs := make([]string, 0, len(inv.SearchPath))
+	dirs = append(dirs, inv.SearchPath...)
+	pathList, _ := envLookup(inv.Env, "PATH")
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	for _, dir := range dirs {
+		if path, err := exec.LookPath(filepath.Join(dir, inv.Program)); err == nil {
+			inv.Path = path
+			return nil
+		}
+	}
+
+	return &ProgramNotFoundError{Program: inv.Program, Searched: dirs}
+}
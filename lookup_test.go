@@ -0,0 +1,136 @@
+//nolint:testpackage // Need access to package-private lookup helpers.
+package sidetable
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func writeExecutable(t *testing.T, dir string, name string) string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755))
+	return path
+}
+
+func TestLookupProgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bit lookup is POSIX-specific")
+	}
+
+	toolBin := t.TempDir()
+	configBin := t.TempDir()
+	pathBin := t.TempDir()
+
+	writeExecutable(t, configBin, "both")
+	wantBoth := writeExecutable(t, toolBin, "both")
+	wantPathOnly := writeExecutable(t, pathBin, "path-only")
+
+	env := []string{"PATH=" + pathBin + string(filepath.ListSeparator) + "relative/bin"}
+
+	t.Run("search path takes precedence over PATH", func(t *testing.T) {
+		inv := Invocation{Program: "both", SearchPath: []string{toolBin, configBin}, Env: env}
+		require.NoError(t, lookupProgram(&inv))
+		require.Equal(t, wantBoth, inv.Path)
+	})
+
+	t.Run("falls back to PATH", func(t *testing.T) {
+		inv := Invocation{Program: "path-only", SearchPath: []string{toolBin}, Env: env}
+		require.NoError(t, lookupProgram(&inv))
+		require.Equal(t, wantPathOnly, inv.Path)
+	})
+
+	t.Run("explicit path", func(t *testing.T) {
+		inv := Invocation{Program: wantBoth, Env: env}
+		require.NoError(t, lookupProgram(&inv))
+		require.Equal(t, wantBoth, inv.Path)
+	})
+
+	t.Run("not found reports searched locations", func(t *testing.T) {
+		inv := Invocation{Program: "missing", SearchPath: []string{toolBin}, Env: env}
+		err := lookupProgram(&inv)
+
+		notFound, ok := AsProgramNotFoundError(err)
+		require.True(t, ok)
+		require.Equal(t, "missing", notFound.Program)
+		require.Equal(t, []string{toolBin, pathBin}, notFound.Searched)
+		require.Contains(t, err.Error(), toolBin)
+	})
+
+	t.Run("missing explicit path", func(t *testing.T) {
+		missing := filepath.Join(toolBin, "nope")
+		inv := Invocation{Program: missing}
+		err := lookupProgram(&inv)
+
+		notFound, ok := AsProgramNotFoundError(err)
+		require.True(t, ok)
+		require.Equal(t, []string{missing}, notFound.Searched)
+	})
+}
+
+func TestResolveInvocationSearchPath(t *testing.T) {
+	workspaceRoot := t.TempDir()
+	configDir := t.TempDir()
+
+	cfg := &config.Config{
+		Directory: ".private",
+		FilePath:  filepath.Join(configDir, "config.yml"),
+		Path:      []string{"{{.ConfigDir}}/bin"},
+		Tools: map[string]config.Tool{
+			"tool":     {Run: "tool", Path: []string{"{{.ToolDir}}/bin"}},
+			"relative": {Run: "tool", Path: []string{"bin"}},
+		},
+	}
+
+	inv, err := resolveInvocation(cfg, "tool", nil, workspaceRoot, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(workspaceRoot, ".private", "tool", "bin"),
+		filepath.Join(configDir, "bin"),
+	}, inv.SearchPath)
+
+	_, err = resolveInvocation(cfg, "relative", nil, workspaceRoot, nil)
+	require.ErrorIs(t, err, errSearchPathRelative)
+}
+
+func TestLookupProgramPathVariable(t *testing.T) {
+	// Windows spells the variable "Path" and finds programs by their extension.
+	pathKey, file := "PATH", "tool"
+	if runtime.GOOS == "windows" {
+		pathKey, file = "Path", "tool.exe"
+		t.Setenv("PATHEXT", ".EXE")
+	}
+	pathBin := t.TempDir()
+	want := writeExecutable(t, pathBin, file)
+
+	inv := Invocation{Program: "tool", Env: []string{pathKey + "=" + pathBin}}
+	require.NoError(t, lookupProgram(&inv))
+	require.Equal(t, want, inv.Path)
+}
+
+func TestEnvLookup(t *testing.T) {
+	env := []string{"PATH=/first", "Path=/second", "HOME=/home/me"}
+
+	value, ok := envLookup(env, "HOME")
+	require.True(t, ok)
+	require.Equal(t, "/home/me", value)
+
+	value, ok = envLookup(env, "PATH")
+	require.True(t, ok)
+	if runtime.GOOS == "windows" {
+		require.Equal(t, "/second", value)
+	} else {
+		require.Equal(t, "/first", value)
+	}
+
+	_, ok = envLookup(env, "MISSING")
+	require.False(t, ok)
+}
@@ -0,0 +1,29 @@
+package sidetable
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// darwinProcStopped is SSTOP from sys/proc.h, the state of a process stopped by a signal.
+const darwinProcStopped = 4
+
+// processStartTime returns when the process pid started, as seconds and microseconds since the epoch.
+func processStartTime(pid int) (string, error) {
+	info, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	if err != nil {
+		return "", err
+	}
+	if int(info.Proc.P_pid) != pid {
+		return "", fmt.Errorf("process %d not found", pid)
+	}
+	start := info.Proc.P_starttime
+	return fmt.Sprintf("%d.%06d", start.Sec, start.Usec), nil
+}
+
+// processStopped reports whether the process pid is stopped, for example by Ctrl-Z.
+func processStopped(pid int) bool {
+	info, err := unix.SysctlKinfoProc("kern.proc.pid", pid)
+	return err == nil && int(info.Proc.P_pid) == pid && info.Proc.P_stat == darwinProcStopped
+}
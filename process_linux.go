@@ -0,0 +1,37 @@
+package sidetable
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// statStartTimeIndex is the index of the start time, field 22 of /proc/<pid>/stat,
+// among the fields that follow the command name.
+const statStartTimeIndex = 22 - 3
+
+// processStartTime returns when the process pid started, in clock ticks after boot.
+func processStartTime(pid int) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", err
+	}
+	// The command name is in parentheses and may contain spaces.
+	fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+	if len(fields) <= statStartTimeIndex {
+		return "", fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	return fields[statStartTimeIndex], nil
+}
+
+// processStopped reports whether the process pid is stopped, for example by Ctrl-Z.
+func processStopped(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// The state follows the command name, which is in parentheses and may contain spaces.
+	fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+	return len(fields) > 0 && fields[0] == "T"
+}
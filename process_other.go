@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+
+package sidetable
+
+import "errors"
+
+// processStartTime is not supported on this platform; services are identified by their PID alone.
+func processStartTime(_ int) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
+// processStopped is not supported on this platform, so a stopped foreground tool is not followed by sidetable.
+func processStopped(_ int) bool {
+	return false
+}
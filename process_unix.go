@@ -0,0 +1,206 @@
+//go:build !windows
+
+package sidetable
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// execSupported reports whether the current process can be replaced with a tool.
+const execSupported = true
+
+// forwardedSignals are the signals passed on to a running tool when InvokeOptions.ForwardSignals is set.
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// configureProcess runs cmd in its own process group, so that signals and cancellation reach
+// every process the tool starts rather than only the direct child.
+//
+// When sidetable owns the terminal the tool reads from, the new group is made the foreground group
+// so that interactive tools keep working. The returned function hands the terminal back to sidetable
+// and must be called once the process has exited.
+func configureProcess(cmd *exec.Cmd) func() {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	tty, ok := foregroundTerminal(cmd.Stdin)
+	if !ok {
+		return func() {}
+	}
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = tty
+	return func() {
+		if cmd.Process != nil {
+			reclaimTerminal(tty, cmd.Process.Pid)
+		}
+	}
+}
+
+// watchStops keeps job control working for a tool started in the foreground by configureProcess.
+//
+// Ctrl-Z only stops the foreground group of the tool, and the shell keeps waiting for sidetable,
+// which would keep waiting for the stopped tool. So when the tool stops, sidetable takes the terminal back
+// and stops itself; once the shell resumes it, the terminal is handed back and the tool continued.
+// The returned function must be called once the process has exited.
+func watchStops(cmd *exec.Cmd) func() {
+	attr := cmd.SysProcAttr
+	if attr == nil || !attr.Foreground || cmd.Process == nil {
+		return func() {}
+	}
+
+	children := make(chan os.Signal, 1)
+	signal.Notify(children, syscall.SIGCHLD)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			// The tool may have stopped before SIGCHLD was caught, so its state is checked first.
+			if processStopped(cmd.Process.Pid) {
+				suspendWithTool(attr.Ctty, cmd.Process.Pid)
+			}
+			select {
+			case <-children:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(children)
+		close(done)
+		<-finished
+	}
+}
+
+// suspendWithTool stops the job of sidetable after the tool in the process group pgid has stopped,
+// and continues the tool once sidetable is resumed.
+func suspendWithTool(tty int, pgid int) {
+	reclaimTerminal(tty, pgid)
+	// The kernel stops sidetable before kill returns, so it returns once the shell resumes the job.
+	// Without job control, for example when SIGTSTP is ignored, it returns right away and the tool is continued.
+	_ = unix.Kill(0, unix.SIGTSTP)
+	// The tool only gets the terminal back when the job was resumed in the foreground.
+	if fg, err := unix.IoctlGetInt(tty, unix.TIOCGPGRP); err == nil && fg == unix.Getpgrp() {
+		setForeground(tty, pgid)
+	}
+	_ = unix.Kill(-pgid, unix.SIGCONT)
+}
+
+// foregroundTerminal returns the descriptor of stdin when it is a terminal whose foreground process group
+// is the group of sidetable.
+func foregroundTerminal(stdin any) (int, bool) {
+	f, ok := stdin.(*os.File)
+	if !ok || f == nil {
+		return 0, false
+	}
+	fd := int(f.Fd())
+	pgrp, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+	if err != nil || pgrp != unix.Getpgrp() {
+		return 0, false
+	}
+	return fd, true
+}
+
+// reclaimTerminal makes the process group of sidetable the foreground group of tty again
+// when the terminal still belongs to the tool group pgid, or to a group that no longer exists.
+// A terminal the shell took back, after sidetable was continued in the background, is left alone.
+func reclaimTerminal(tty int, pgid int) {
+	fg, err := unix.IoctlGetInt(tty, unix.TIOCGPGRP)
+	if err != nil || fg == unix.Getpgrp() {
+		return
+	}
+	if fg > 0 && fg != pgid && !errors.Is(unix.Kill(-fg, 0), unix.ESRCH) {
+		return
+	}
+	setForeground(tty, unix.Getpgrp())
+}
+
+// setForeground makes pgid the foreground process group of tty.
+func setForeground(tty int, pgid int) {
+	// A background process changing the foreground group is stopped by SIGTTOU unless it ignores it.
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	_ = unix.IoctlSetPointerInt(tty, unix.TIOCSPGRP, pgid)
+}
+
+// signalProcess sends sig to the process group led by p.
+func signalProcess(p *os.Process, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.Signal(sig)
+	}
+	err := unix.Kill(-p.Pid, s)
+	if errors.Is(err, unix.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// interruptProcess asks the process group led by p to terminate.
+func interruptProcess(p *os.Process) error {
+	return signalProcess(p, syscall.SIGTERM)
+}
+
+// killProcessGroup kills the processes left in the group led by p after the leader has exited.
+func killProcessGroup(p *os.Process) {
+	_ = signalProcess(p, syscall.SIGKILL)
+}
+
+// execProcess replaces the current process with the program at path.
+func execProcess(path string, argv []string, env []string) error {
+	// #nosec G204 -- command/args are from user-owned config; explicit delegation is intended.
+	return unix.Exec(path, argv, env)
+}
+
+// configureService detaches a service from the terminal of sidetable in a session of its own,
+// which also makes it the leader of its process group.
+func configureService(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// serviceAlive reports whether the process pid is running. Zombies, which have exited but
+// were not reaped yet, do not count as running, and neither do processes that may not be signalled,
+// since services run as the user who started them.
+func serviceAlive(pid int) bool {
+	if err := unix.Kill(pid, 0); err != nil {
+		return false
+	}
+	// Only Linux exposes the process state in /proc; elsewhere the process is taken to be running.
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	// The state follows the command name, which is in parentheses and may contain spaces.
+	_, rest, found := strings.Cut(string(data[bytes.LastIndexByte(data, ')')+1:]), " ")
+	return !found || !strings.HasPrefix(rest, "Z")
+}
+
+// signalService sends the named signal, such as "SIGTERM", to the process group of the service pid.
+func signalService(pid int, name string) error {
+	sig := unix.SignalNum(name)
+	if sig == 0 {
+		return fmt.Errorf("unknown signal %q", name)
+	}
+	err := unix.Kill(-pid, sig)
+	if errors.Is(err, unix.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// exitSignal returns the signal that terminated the process, if any.
+func exitSignal(state *os.ProcessState) (syscall.Signal, bool) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+	return status.Signal(), true
+}
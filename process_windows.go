@@ -0,0 +1,106 @@
+//go:build windows
+
+package sidetable
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// execSupported reports whether the current process can be replaced with a tool.
+// Windows has no exec, so tools always run as child processes.
+const execSupported = false
+
+// forwardedSignals are the signals handled while a tool runs when InvokeOptions.ForwardSignals is set.
+// The console delivers Ctrl-C to the tool itself, so sidetable only has to survive it and wait.
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+// configureProcess leaves cmd in the process group of sidetable, so that the tool keeps receiving Ctrl-C
+// from the console.
+func configureProcess(_ *exec.Cmd) func() {
+	return func() {}
+}
+
+// watchStops does nothing on Windows, which has no job control.
+func watchStops(_ *exec.Cmd) func() {
+	return func() {}
+}
+
+// signalProcess delivers sig to p. Windows cannot send signals to other processes;
+// Ctrl-C already reached the tool through the console, so os.Interrupt is not sent again.
+func signalProcess(p *os.Process, sig os.Signal) error {
+	if sig == os.Interrupt {
+		return nil
+	}
+	return p.Signal(sig)
+}
+
+// interruptProcess terminates p. Windows cannot deliver SIGTERM, so the process is killed right away.
+func interruptProcess(p *os.Process) error {
+	return p.Kill()
+}
+
+// killProcessGroup does nothing on Windows, where tools do not run in a separate process group.
+func killProcessGroup(_ *os.Process) {}
+
+// execProcess is never called on Windows; see execSupported.
+func execProcess(_ string, _ []string, _ []string) error {
+	return errors.ErrUnsupported
+}
+
+// configureService detaches a service from the console of sidetable in a process group of its own.
+func configureService(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+}
+
+// serviceAlive reports whether the process pid is running.
+func serviceAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err = windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stillActive is the exit code Windows reports for processes that have not exited.
+const stillActive = 259
+
+// processStartTime returns when the process pid was created, in 100-nanosecond intervals since 1601.
+func processStartTime(pid int) (string, error) {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(h)
+	var created, exited, kernel, user windows.Filetime
+	if err = windows.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(uint64(created.HighDateTime)<<32|uint64(created.LowDateTime), 10), nil
+}
+
+// signalService terminates the service pid. Windows cannot deliver signals, so the signal name is ignored.
+func signalService(pid int, _ string) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return os.ErrProcessDone
+	}
+	return p.Kill()
+}
+
+// exitSignal reports no signal; processes on Windows always exit with a status.
+func exitSignal(_ *os.ProcessState) (syscall.Signal, bool) {
+	return 0, false
+}
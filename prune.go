@@ -0,0 +1,232 @@
+package sidetable
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PruneReason describes why a directory is a prune candidate.
+type PruneReason string
+
+const (
+	// PruneReasonOrphaned marks directories that do not belong to any configured tool.
+	PruneReasonOrphaned PruneReason = "orphaned"
+	// PruneReasonStale marks tool directories that have not been modified for longer than the requested age.
+	PruneReasonStale PruneReason = "stale"
+	// PruneReasonBackup marks backups kept by Import that have not been modified for longer than the requested age.
+	PruneReasonBackup PruneReason = "backup"
+)
+
+// PruneCandidate is a directory in the tool area that can be removed.
+type PruneCandidate struct {
+	DirStatus
+
+	Reason PruneReason
+}
+
+var errAreaOutsideWorkspace = errors.New("tool area is outside the workspace root")
+
+// ErrToolDirInUse is returned when removing or moving the directory of a running service or of a locked tool.
+var ErrToolDirInUse = errors.New("tool directory is in use")
+
+// PruneCandidates returns orphaned directories in the tool area.
+// When olderThan is positive, configured tool directories and backups not modified within olderThan are included as well.
+// Directories of running services and of tools whose lock is held are never included.
+func (w *Workspace) PruneCandidates(olderThan time.Duration, now time.Time) ([]PruneCandidate, error) {
+	status, err := w.AreaStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]PruneCandidate, 0, len(status.Orphans))
+	for _, dir := range status.Orphans {
+		candidates = append(candidates, PruneCandidate{DirStatus: dir, Reason: PruneReasonOrphaned})
+	}
+	if olderThan > 0 {
+		cutoff := now.Add(-olderThan)
+		for _, dir := range status.Tools {
+			if dir.Exists && dir.ModTime.Before(cutoff) {
+				candidates = append(candidates, PruneCandidate{DirStatus: dir, Reason: PruneReasonStale})
+			}
+		}
+		for _, dir := range status.Backups {
+			if dir.ModTime.Before(cutoff) {
+				candidates = append(candidates, PruneCandidate{DirStatus: dir, Reason: PruneReasonBackup})
+			}
+		}
+	}
+
+	// Symbolic links are removed without touching their targets, so only directories are checked.
+	idle := candidates[:0]
+	for _, candidate := range candidates {
+		if candidate.Symlink {
+			idle = append(idle, candidate)
+			continue
+		}
+		reason, inUseErr := w.areaDirInUse(candidate.Name)
+		if inUseErr != nil {
+			return nil, inUseErr
+		}
+		if reason == "" {
+			idle = append(idle, candidate)
+		}
+	}
+	return idle, nil
+}
+
+// RemoveAreaDir removes the directory called name directly under the tool area.
+// A symbolic link is removed without touching its target, and links inside the directory are never followed.
+// It refuses to act when the tool area itself is a symbolic link or lies outside the workspace root,
+// and fails with ErrToolDirInUse while the directory belongs to a running service or a locked tool.
+func (w *Workspace) RemoveAreaDir(name string) error {
+	if w == nil || w.config == nil {
+		return errors.New("workspace is not initialized")
+	}
+	if err := checkAreaEntryName(name); err != nil {
+		return err
+	}
+
+	area := w.AreaDir()
+	if err := w.checkAreaInWorkspace(area); err != nil {
+		return err
+	}
+
+	path := filepath.Join(area, name)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return os.Remove(path)
+	}
+	if err = w.checkAreaDirIdle(name); err != nil {
+		return err
+	}
+	// RemoveAll unlinks symbolic links instead of descending into them.
+	return os.RemoveAll(path)
+}
+
+// MoveToolDir renames the directory of tool oldName in the tool area to the directory of newName,
+// for a tool that was renamed in the config. It reports whether a directory was moved:
+// nothing is done when oldName has no directory.
+// See CheckMoveToolDir for when the directory cannot be moved.
+func (w *Workspace) MoveToolDir(oldName, newName string) (bool, error) {
+	if err := w.CheckMoveToolDir(oldName, newName); err != nil {
+		return false, err
+	}
+
+	area := w.AreaDir()
+	src := filepath.Join(area, oldName)
+	if _, err := os.Lstat(src); errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if err := os.Rename(src, filepath.Join(area, newName)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckMoveToolDir reports why MoveToolDir cannot move the directory of oldName to newName, if it has one.
+// It fails with ErrToolDirInUse while the directory belongs to a running service or a locked tool,
+// and when a directory for newName already exists.
+func (w *Workspace) CheckMoveToolDir(oldName, newName string) error {
+	if w == nil || w.config == nil {
+		return errors.New("workspace is not initialized")
+	}
+	if err := checkAreaEntryName(oldName); err != nil {
+		return err
+	}
+	if err := checkAreaEntryName(newName); err != nil {
+		return err
+	}
+
+	area := w.AreaDir()
+	if _, err := os.Lstat(filepath.Join(area, oldName)); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := w.checkAreaInWorkspace(area); err != nil {
+		return err
+	}
+	if err := w.checkAreaDirIdle(oldName); err != nil {
+		return err
+	}
+	dest := filepath.Join(area, newName)
+	if _, err := os.Lstat(dest); err == nil {
+		return fmt.Errorf("tool directory %s already exists", dest)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// areaDirInUse returns why the directory called name in the tool area must be left alone,
+// or an empty string when nothing uses it.
+func (w *Workspace) areaDirInUse(name string) (string, error) {
+	service, err := w.serviceStatus(name)
+	if err != nil {
+		return "", err
+	}
+	if service.Running {
+		return fmt.Sprintf("service is running (PID %d)", service.State.PID), nil
+	}
+
+	pid, locked, err := lockHolder(filepath.Join(w.AreaDir(), name))
+	if err != nil || !locked {
+		return "", err
+	}
+	return "locked by " + (&LockError{PID: pid}).holder(), nil
+}
+
+// checkAreaDirIdle fails with ErrToolDirInUse when areaDirInUse reports a reason.
+func (w *Workspace) checkAreaDirIdle(name string) error {
+	reason, err := w.areaDirInUse(name)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return fmt.Errorf("%w: %s: %s", ErrToolDirInUse, filepath.Join(w.AreaDir(), name), reason)
+	}
+	return nil
+}
+
+// checkAreaEntryName ensures name refers to an entry directly under the tool area.
+func checkAreaEntryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid directory name %q", name)
+	}
+	return nil
+}
+
+// checkAreaInWorkspace ensures no path element between the workspace root and area is a symbolic link,
+// so removing entries in area cannot reach outside the workspace.
+func (w *Workspace) checkAreaInWorkspace(area string) error {
+	rel, err := filepath.Rel(w.rootDir, area)
+	if err != nil {
+		return err
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s", errAreaOutsideWorkspace, area)
+	}
+
+	current := w.rootDir
+	for _, element := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, element)
+		info, statErr := os.Lstat(current)
+		if statErr != nil {
+			return statErr
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %s is a symbolic link", errAreaOutsideWorkspace, current)
+		}
+	}
+	return nil
+}
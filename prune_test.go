@@ -0,0 +1,170 @@
+package sidetable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/filelock"
+)
+
+// holdToolLock holds the lock in dir, as a run of a tool with a lock would, until the test ends.
+func holdToolLock(t *testing.T, dir string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	f, err := os.OpenFile(filepath.Join(dir, sidetable.ToolLockFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	acquired, err := filelock.TryLock(f, false)
+	require.NoError(t, err)
+	require.True(t, acquired)
+}
+
+func TestWorkspacePruneCandidates(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"fresh": {Run: "echo"},
+			"stale": {Run: "echo"},
+		},
+		nil,
+	)
+
+	area := ws.AreaDir()
+	for _, name := range []string{"fresh", "stale", "old"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(area, name), 0o755))
+	}
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(area, "stale"), past, past))
+
+	reasons := func(candidates []sidetable.PruneCandidate) map[string]sidetable.PruneReason {
+		result := make(map[string]sidetable.PruneReason, len(candidates))
+		for _, candidate := range candidates {
+			result[candidate.Name] = candidate.Reason
+		}
+		return result
+	}
+
+	candidates, err := ws.PruneCandidates(0, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, map[string]sidetable.PruneReason{"old": sidetable.PruneReasonOrphaned}, reasons(candidates))
+
+	candidates, err = ws.PruneCandidates(7*24*time.Hour, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, map[string]sidetable.PruneReason{
+		"old":   sidetable.PruneReasonOrphaned,
+		"stale": sidetable.PruneReasonStale,
+	}, reasons(candidates))
+}
+
+func TestWorkspaceRemoveAreaDirDoesNotFollowSymlinks(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"a": {Run: "echo"}}, nil)
+
+	outside := t.TempDir()
+	keep := filepath.Join(outside, "keep")
+	require.NoError(t, os.WriteFile(keep, []byte("x"), 0o600))
+
+	area := ws.AreaDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "old"), 0o755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(area, "old", "inner-link")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(area, "link")))
+
+	require.NoError(t, ws.RemoveAreaDir("old"))
+	require.NoError(t, ws.RemoveAreaDir("link"))
+	require.NoDirExists(t, filepath.Join(area, "old"))
+	require.NoFileExists(t, filepath.Join(area, "link"))
+	require.FileExists(t, keep)
+
+	require.Error(t, ws.RemoveAreaDir(".."))
+	require.Error(t, ws.RemoveAreaDir("a/../.."))
+}
+
+func TestWorkspaceRemoveAreaDirRefusesSymlinkedArea(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"a": {Run: "echo"}}, nil)
+
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(outside, "old"), 0o755))
+	require.NoError(t, os.Symlink(outside, ws.AreaDir()))
+
+	require.ErrorContains(t, ws.RemoveAreaDir("old"), "outside the workspace")
+	require.DirExists(t, filepath.Join(outside, "old"))
+}
+
+func TestWorkspacePruneCandidatesNeverIncludeConfiguredTools(t *testing.T) {
+	tools := map[string]config.Tool{
+		"plain":  {Run: "echo"},
+		"go1.22": {Run: "echo"},
+		"db.cli": {Run: "echo"},
+	}
+	ws := setupTestWorkspace(t, tools, nil)
+
+	area := ws.AreaDir()
+	for name := range tools {
+		dir, err := ws.ToolDir(name)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "old"), 0o755))
+
+	candidates, err := ws.PruneCandidates(0, time.Now())
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.Equal(t, "old", candidates[0].Name)
+
+	status, err := ws.AreaStatus()
+	require.NoError(t, err)
+	for _, dir := range status.Tools {
+		require.Contains(t, tools, dir.Name)
+	}
+}
+
+func TestWorkspacePruneLeavesLockedToolsAlone(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"busy": {Run: "echo"}}, nil)
+
+	area := ws.AreaDir()
+	for _, name := range []string{"old", "locked-old"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(area, name), 0o755))
+	}
+	holdToolLock(t, filepath.Join(area, "busy"))
+	holdToolLock(t, filepath.Join(area, "locked-old"))
+
+	candidates, err := ws.PruneCandidates(time.Hour, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	require.Equal(t, "old", candidates[0].Name)
+
+	require.ErrorIs(t, ws.RemoveAreaDir("locked-old"), sidetable.ErrToolDirInUse)
+	require.DirExists(t, filepath.Join(area, "locked-old"))
+}
+
+func TestWorkspaceMoveToolDir(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"a": {Run: "echo"}, "b": {Run: "echo"}}, nil)
+	area := ws.AreaDir()
+
+	moved, err := ws.MoveToolDir("a", "c")
+	require.NoError(t, err)
+	require.False(t, moved)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "a"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(area, "a", "state"), []byte("x"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "b"), 0o755))
+	_, err = ws.MoveToolDir("a", "b")
+	require.ErrorContains(t, err, "already exists")
+
+	moved, err = ws.MoveToolDir("a", "c")
+	require.NoError(t, err)
+	require.True(t, moved)
+	require.FileExists(t, filepath.Join(area, "c", "state"))
+	require.NoDirExists(t, filepath.Join(area, "a"))
+
+	holdToolLock(t, filepath.Join(area, "c"))
+	_, err = ws.MoveToolDir("c", "d")
+	require.ErrorIs(t, err, sidetable.ErrToolDirInUse)
+	require.DirExists(t, filepath.Join(area, "c"))
+}
@@ -0,0 +1,88 @@
+package sidetable
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/registry"
+)
+
+// KnownWorkspace is a workspace Run has been used in, as recorded in the registry
+// under the XDG state directory.
+type KnownWorkspace struct {
+	Root     string
+	LastUsed time.Time
+	// Exists is false when the root directory no longer exists.
+	Exists bool
+	// Area describes the tool area recorded when the workspace was last used.
+	Area DirStatus
+}
+
+// KnownWorkspaces returns the recorded workspaces, most recently used first.
+func KnownWorkspaces() ([]KnownWorkspace, error) {
+	path, err := registry.Path()
+	if err != nil {
+		return nil, err
+	}
+	r, err := registry.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make([]KnownWorkspace, 0, len(r.Workspaces))
+	for _, recorded := range r.Workspaces {
+		workspace := KnownWorkspace{
+			Root:     recorded.Root,
+			LastUsed: recorded.LastUsed,
+			Exists:   isDir(recorded.Root),
+			Area:     DirStatus{Name: filepath.Base(recorded.Area), Path: recorded.Area},
+		}
+		if workspace.Exists {
+			if workspace.Area, err = statDir(recorded.Area); err != nil {
+				return nil, err
+			}
+		}
+		known = append(known, workspace)
+	}
+	return known, nil
+}
+
+// ForgetMissingWorkspaces removes workspaces whose root directory no longer exists from the registry
+// and returns their roots.
+func ForgetMissingWorkspaces() ([]string, error) {
+	path, err := registry.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	var forgotten []string
+	err = registry.Update(path, func(r *registry.Registry) bool {
+		for _, recorded := range append([]registry.Workspace(nil), r.Workspaces...) {
+			if !isDir(recorded.Root) {
+				r.Forget(recorded.Root)
+				forgotten = append(forgotten, recorded.Root)
+			}
+		}
+		return len(forgotten) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return forgotten, nil
+}
+
+// recordUse records the workspace in the registry.
+// Recording is best effort: a broken or unwritable registry must not prevent tools from running.
+func (w *Workspace) recordUse(now time.Time) {
+	root, err := filepath.Abs(w.rootDir)
+	if err != nil {
+		return
+	}
+	_ = registry.Record(root, areaDir(root, w.config), now)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,63 @@
+package sidetable_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func TestWorkspaceRunRecordsKnownWorkspace(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"hello": {Run: "echo"}}, nil)
+	require.NoError(t, os.MkdirAll(filepath.Join(ws.AreaDir(), "hello"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(ws.AreaDir(), "hello", "a"), []byte("abc"), 0o600))
+
+	known, err := sidetable.KnownWorkspaces()
+	require.NoError(t, err)
+	require.Empty(t, known)
+
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}
+	require.NoError(t, ws.Run(context.Background(), "hello", nil, opts))
+
+	known, err = sidetable.KnownWorkspaces()
+	require.NoError(t, err)
+	require.Len(t, known, 1)
+	require.Equal(t, ws.Root(), known[0].Root)
+	require.True(t, known[0].Exists)
+	require.False(t, known[0].LastUsed.IsZero())
+	require.Equal(t, ws.AreaDir(), known[0].Area.Path)
+	require.Equal(t, int64(3), known[0].Area.Size)
+}
+
+func TestForgetMissingWorkspaces(t *testing.T) {
+	removed := setupTestWorkspace(t, map[string]config.Tool{"hello": {Run: "echo"}}, nil)
+	stateDir := os.Getenv("SIDETABLE_STATE_DIR")
+	kept := setupTestWorkspace(t, map[string]config.Tool{"hello": {Run: "echo"}}, nil)
+	t.Setenv("SIDETABLE_STATE_DIR", stateDir)
+
+	opts := sidetable.InvokeOptions{Stdout: io.Discard, Stderr: io.Discard}
+	require.NoError(t, removed.Run(context.Background(), "hello", nil, opts))
+	require.NoError(t, kept.Run(context.Background(), "hello", nil, opts))
+	require.NoError(t, os.RemoveAll(removed.Root()))
+
+	known, err := sidetable.KnownWorkspaces()
+	require.NoError(t, err)
+	require.Len(t, known, 2)
+	require.Equal(t, kept.Root(), known[0].Root)
+	require.False(t, known[1].Exists)
+
+	forgotten, err := sidetable.ForgetMissingWorkspaces()
+	require.NoError(t, err)
+	require.Equal(t, []string{removed.Root()}, forgotten)
+
+	known, err = sidetable.KnownWorkspaces()
+	require.NoError(t, err)
+	require.Len(t, known, 1)
+	require.Equal(t, kept.Root(), known[0].Root)
+}
@@ -0,0 +1,131 @@
+package sidetable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// AttemptEnv is set to the attempt number, starting at 1, for tools with a retry policy.
+const AttemptEnv = "SIDETABLE_ATTEMPT"
+
+// RetryPolicy describes how a failing invocation is run again.
+type RetryPolicy struct {
+	// Attempts is the total number of runs, including the first one. Values below 2 disable retries.
+	Attempts int
+	// Delay is the wait before the first retry.
+	Delay time.Duration
+	// Backoff multiplies the delay after each retry, up to five minutes. Values below 1 keep the delay constant.
+	Backoff float64
+	// OnExitCodes limits retries to these exit codes. Empty retries any non-zero exit code.
+	OnExitCodes []int
+}
+
+// Enabled reports whether the policy runs failing invocations again.
+func (p RetryPolicy) Enabled() bool {
+	return p.Attempts > 1
+}
+
+// retries reports whether a failed attempt may be run again.
+// Timeouts and processes killed by a signal, such as Ctrl-C, are never retried.
+func (p RetryPolicy) retries(err error) bool {
+	invErr, ok := AsInvocationError(err)
+	if !ok || invErr.Signal != nil {
+		return false
+	}
+	return len(p.OnExitCodes) == 0 || slices.Contains(p.OnExitCodes, invErr.Code)
+}
+
+// maxRetryBackoffDelay caps the delay that Backoff grows to, so that it cannot overflow.
+// A configured Delay above it is kept as it is.
+const maxRetryBackoffDelay = 5 * time.Minute
+
+// nextDelay returns the delay before the retry following one that waited delay.
+func (p RetryPolicy) nextDelay(delay time.Duration) time.Duration {
+	if p.Backoff <= 1 || delay >= maxRetryBackoffDelay {
+		return delay
+	}
+	// The product is compared as a float, since converting one beyond the range of Duration is undefined.
+	next := float64(delay) * p.Backoff
+	if next >= float64(maxRetryBackoffDelay) {
+		return maxRetryBackoffDelay
+	}
+	return time.Duration(next)
+}
+
+// executeWithRetry executes inv, running it again as its retry policy allows.
+// Every attempt is recorded in the history, and a line on stderr separates the output of consecutive attempts.
+func (w *Workspace) executeWithRetry(
+	ctx context.Context,
+	name string,
+	userArgs []string,
+	inv Invocation,
+	opts InvokeOptions,
+) error {
+	policy := inv.Retry
+	if !policy.Enabled() {
+		start := time.Now()
+		err := w.execute(ctx, inv, opts)
+		w.recordHistory(name, userArgs, inv, opts, start, err)
+		return err
+	}
+
+	stderr := stderrOf(opts)
+	delay := policy.Delay
+	for attempt := 1; ; attempt++ {
+		attemptInv := inv
+		attemptInv.Env = setEnv(inv.Env, AttemptEnv, strconv.Itoa(attempt))
+
+		start := time.Now()
+		err := w.execute(ctx, attemptInv, opts)
+		w.recordHistory(name, userArgs, attemptInv, opts, start, err)
+		if invErr, ok := AsInvocationError(err); ok {
+			invErr.Attempts = attempt
+		}
+		if err == nil || attempt >= policy.Attempts || !policy.retries(err) || ctx.Err() != nil {
+			return err
+		}
+
+		reportRetry(stderr, attempt, policy.Attempts, err, delay)
+		if waitErr := sleepContext(ctx, delay); waitErr != nil {
+			return err
+		}
+		delay = policy.nextDelay(delay)
+	}
+}
+
+func reportRetry(w io.Writer, attempt int, attempts int, err error, delay time.Duration) {
+	invErr, _ := AsInvocationError(err)
+	msg := fmt.Sprintf("sidetable: attempt %d/%d failed with exit code %d", attempt, attempts, invErr.Code)
+	if delay > 0 {
+		msg += fmt.Sprintf(", retrying in %s", delay)
+	} else {
+		msg += ", retrying"
+	}
+	fmt.Fprintln(w, msg)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setEnv returns a copy of env with key set to value.
+func setEnv(env []string, key string, value string) []string {
+	envMap := envMapFromSlice(env)
+	envMap[key] = value
+	return envSliceFromMap(envMap)
+}
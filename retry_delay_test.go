@@ -0,0 +1,27 @@
+//nolint:testpackage // Need access to the package-private delay computation.
+package sidetable
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyNextDelayIsCapped(t *testing.T) {
+	policy := RetryPolicy{Backoff: 2}
+	require.Equal(t, 2*time.Second, policy.nextDelay(time.Second))
+
+	delay := time.Second
+	for range 100 {
+		delay = policy.nextDelay(delay)
+		require.Positive(t, delay)
+	}
+	require.Equal(t, maxRetryBackoffDelay, delay)
+
+	huge := RetryPolicy{Backoff: math.MaxFloat64}
+	require.Equal(t, maxRetryBackoffDelay, huge.nextDelay(time.Millisecond))
+	require.Equal(t, time.Hour, huge.nextDelay(time.Hour), "a configured delay above the cap is kept")
+	require.Equal(t, time.Second, RetryPolicy{Backoff: 0.5}.nextDelay(time.Second))
+}
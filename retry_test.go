@@ -0,0 +1,67 @@
+//go:build !windows
+
+package sidetable_test
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func retryTool(script string, retry config.Retry) config.Tool {
+	tool := shellTool(script)
+	tool.Retry = retry
+	return tool
+}
+
+func TestWorkspaceRunRetriesUntilSuccess(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"flaky": retryTool(`echo "attempt $SIDETABLE_ATTEMPT"; [ "$SIDETABLE_ATTEMPT" -ge 3 ]`, config.Retry{
+			Attempts: 5,
+			Delay:    "1ms",
+			Backoff:  2,
+		}),
+	}, nil)
+
+	var stdout, stderr bytes.Buffer
+	err := ws.Run(context.Background(), "flaky", nil, sidetable.InvokeOptions{Stdout: &stdout, Stderr: &stderr})
+	require.NoError(t, err)
+	require.Equal(t, "attempt 1\nattempt 2\nattempt 3\n", stdout.String())
+	require.Equal(t,
+		"sidetable: attempt 1/5 failed with exit code 1, retrying in 1ms\n"+
+			"sidetable: attempt 2/5 failed with exit code 1, retrying in 2ms\n",
+		stderr.String())
+
+	records, err := ws.History(sidetable.HistoryFilter{Entry: "flaky"})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for i, record := range records {
+		require.Equal(t, strconv.Itoa(i+1), record.Env[sidetable.AttemptEnv])
+	}
+	require.True(t, records[2].Succeeded())
+}
+
+func TestWorkspaceRunReportsAttempts(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"broken":    retryTool("exit 2", config.Retry{Attempts: 3}),
+		"selective": retryTool("exit 2", config.Retry{Attempts: 3, OnExitCodes: []int{75}}),
+		"plain":     shellTool("exit 2"),
+	}, nil)
+
+	for name, attempts := range map[string]int{"broken": 3, "selective": 1, "plain": 1} {
+		var stderr bytes.Buffer
+		err := ws.Run(context.Background(), name, nil, sidetable.InvokeOptions{Stderr: &stderr})
+		invErr, ok := sidetable.AsInvocationError(err)
+		require.True(t, ok, "expected invocation error for %s, got %v", name, err)
+		require.Equal(t, 2, invErr.Code, name)
+		require.Equal(t, attempts, invErr.Attempts, name)
+		require.Equal(t, attempts-1, strings.Count(stderr.String(), "retrying"), name)
+	}
+}
@@ -0,0 +1,427 @@
+package sidetable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/sushichan044/sidetable/internal/fileutil"
+)
+
+const (
+	// ToolPIDFileName records the running process of a service tool in its tool directory.
+	ToolPIDFileName = ".sidetable.pid"
+	// ToolLogFileName collects the output of a service tool in its tool directory.
+	ToolLogFileName = ".sidetable.log"
+	// ToolOldLogFileName keeps the previous log of a service tool after the log was rotated.
+	ToolOldLogFileName = ToolLogFileName + ".1"
+	// ServiceLogMaxSize is the size beyond which the log of a service is rotated when the service starts.
+	ServiceLogMaxSize = 10 << 20
+
+	serviceFilePerm = 0o644
+	// defaultStopSignal stops services without a stop_signal.
+	defaultStopSignal = "SIGTERM"
+	// killSignalName stops services that outlive the grace period.
+	killSignalName = "SIGKILL"
+	// serviceStartCheckDelay is how long StartService watches a new service for an immediate exit.
+	serviceStartCheckDelay = 300 * time.Millisecond
+	// serviceStopPollInterval is how often StopService checks whether a stopping service has exited.
+	serviceStopPollInterval = 50 * time.Millisecond
+)
+
+var (
+	// ErrNotService is returned when a service command is used with a tool that is not a service.
+	ErrNotService = errors.New("tool is not a service; set service: true in its config")
+	// ErrServiceRunning is returned when starting a service that is already running.
+	ErrServiceRunning = errors.New("service is already running")
+	// ErrServiceNotRunning is returned when stopping a service that is not running.
+	ErrServiceNotRunning = errors.New("service is not running")
+	// ErrServiceTool is returned when Run is asked to run a service tool outside the command line.
+	ErrServiceTool = errors.New("tool is a service; start it with StartService instead")
+)
+
+// internalToolFiles are files sidetable keeps in tool directories. They are specific to
+// the machine and are left out of exported archives.
+var internalToolFiles = []string{ToolLockFileName, ToolPIDFileName, ToolLogFileName, ToolOldLogFileName}
+
+// ServiceState is recorded in the PID file of a started service.
+type ServiceState struct {
+	PID int `json:"pid"`
+	// ProcessStart identifies the process together with PID, so that a process that later gets the same PID
+	// is not taken for the service. Its format depends on the platform.
+	ProcessStart string `json:"process_start,omitempty"`
+	// Entry is the tool or alias name the service was started with.
+	Entry string `json:"entry"`
+	// UserArgs are the arguments given when the service was started; restarts reuse them.
+	UserArgs  []string  `json:"user_args"`
+	Program   string    `json:"program"`
+	Args      []string  `json:"args"`
+	Cwd       string    `json:"cwd"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ServiceStatus describes a service tool.
+type ServiceStatus struct {
+	Name    string
+	Running bool
+	// Stale is set when a PID file exists but its process no longer runs, or the PID now belongs to another process.
+	Stale bool
+	// State is the recorded state of the last start, or nil when none is recorded.
+	State   *ServiceState
+	PIDFile string
+	LogFile string
+}
+
+// Services returns the status of every service tool, sorted by name.
+func (w *Workspace) Services() ([]ServiceStatus, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+
+	var services []ServiceStatus
+	for _, name := range w.config.ToolNames() {
+		if !w.config.Tools[name].Service {
+			continue
+		}
+		status, err := w.serviceStatus(name)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, status)
+	}
+	return services, nil
+}
+
+// ServiceStatus returns the status of the service tool name refers to.
+func (w *Workspace) ServiceStatus(name string) (ServiceStatus, error) {
+	toolName, err := w.serviceToolName(name)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	return w.serviceStatus(toolName)
+}
+
+// StartService starts a service tool or alias in the background.
+//
+// The process is detached from the terminal, its output is appended to the log file in the tool directory
+// and its PID is recorded next to it. A log larger than ServiceLogMaxSize is moved to ToolOldLogFileName first,
+// replacing the previous one. StartService fails with ErrServiceRunning when the service already runs,
+// and reports an error when the process exits right after starting.
+//
+// The tool lock is held while the service is started, so concurrent starts and stops of it do not overlap.
+func (w *Workspace) StartService(name string, userArgs []string) (ServiceStatus, error) {
+	inv, err := w.Resolve(name, userArgs)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	if !inv.Service {
+		return ServiceStatus{}, fmt.Errorf("%w: %s", ErrNotService, inv.ToolName)
+	}
+
+	lock, err := w.lockService(context.Background(), inv.ToolName)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	defer lock.Release()
+	return w.startService(name, userArgs, inv)
+}
+
+// startService starts the service inv resolved from name and userArgs. The caller holds the tool lock.
+func (w *Workspace) startService(name string, userArgs []string, inv Invocation) (ServiceStatus, error) {
+	status, err := w.serviceStatus(inv.ToolName)
+	if err != nil {
+		return status, err
+	}
+	if status.Running {
+		return status, fmt.Errorf("%w: %s (PID %d)", ErrServiceRunning, inv.ToolName, status.State.PID)
+	}
+
+	if w.config.GitExclude {
+		if _, err = w.EnsureGitExclude(); err != nil {
+			return status, fmt.Errorf("failed to exclude the tool area from git: %w", err)
+		}
+	}
+	start := time.Now()
+	w.recordUse(start)
+
+	cmd, err := w.startServiceProcess(inv, status.LogFile, start)
+	if err != nil {
+		return status, err
+	}
+	processStart, err := processStartTime(cmd.Process.Pid)
+	if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		_ = signalService(cmd.Process.Pid, killSignalName)
+		_ = cmd.Wait()
+		return status, fmt.Errorf("failed to identify the process of %s: %w", inv.ToolName, err)
+	}
+
+	state := &ServiceState{
+		PID:          cmd.Process.Pid,
+		ProcessStart: processStart,
+		Entry:        name,
+		UserArgs:     userArgs,
+		Program:      inv.Program,
+		Args:         inv.Args,
+		StartedAt:    start.UTC(),
+	}
+	state.Cwd, _ = os.Getwd()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return status, err
+	}
+	if err = fileutil.WriteFileAtomic(status.PIDFile, append(data, '\n'), serviceFilePerm); err != nil {
+		_ = signalService(state.PID, killSignalName)
+		return status, err
+	}
+
+	// Waiting also reaps the process when it exits while the current process is still alive.
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	select {
+	case waitErr := <-exited:
+		_ = os.Remove(status.PIDFile)
+		return status, fmt.Errorf(
+			"service %s exited right after starting (%v); see %s", inv.ToolName, waitErr, status.LogFile)
+	case <-time.After(serviceStartCheckDelay):
+	}
+
+	status.Running, status.Stale, status.State = true, false, state
+	return status, nil
+}
+
+// startServiceProcess starts the process of a service with its output appended to logFile.
+func (w *Workspace) startServiceProcess(inv Invocation, logFile string, start time.Time) (*exec.Cmd, error) {
+	if err := os.MkdirAll(filepath.Dir(logFile), toolDirPerm); err != nil {
+		return nil, err
+	}
+	if err := rotateServiceLog(logFile); err != nil {
+		return nil, err
+	}
+	logOut, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, serviceFilePerm)
+	if err != nil {
+		return nil, err
+	}
+	defer logOut.Close()
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		return nil, err
+	}
+	defer stdin.Close()
+
+	path := inv.Path
+	if path == "" {
+		path = inv.Program
+	}
+	fmt.Fprintf(logOut, "--- sidetable: starting %s at %s ---\n", inv.ToolName, start.Format(time.RFC3339))
+
+	// #nosec G204 -- command/args are from user-owned config; explicit delegation is intended.
+	cmd := exec.Command(path, inv.Args...)
+	cmd.Args[0] = inv.Program
+	cmd.Env = inv.Env
+	cmd.Stdin = stdin
+	cmd.Stdout = logOut
+	cmd.Stderr = logOut
+	configureService(cmd)
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// StopService stops a running service tool or alias with the stop signal of the tool.
+// A service that is still running after the grace period, or when ctx is done, is killed.
+//
+// It fails with ErrServiceNotRunning when the service is not running; a stale PID file is removed then.
+func (w *Workspace) StopService(ctx context.Context, name string) (ServiceStatus, error) {
+	toolName, err := w.serviceToolName(name)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	lock, err := w.lockService(ctx, toolName)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	defer lock.Release()
+	return w.stopService(ctx, toolName)
+}
+
+// stopService stops the service tool toolName. The caller holds the tool lock.
+func (w *Workspace) stopService(ctx context.Context, toolName string) (ServiceStatus, error) {
+	status, err := w.serviceStatus(toolName)
+	if err != nil {
+		return status, err
+	}
+	if !status.Running {
+		if status.Stale {
+			_ = os.Remove(status.PIDFile)
+		}
+		return status, fmt.Errorf("%w: %s", ErrServiceNotRunning, toolName)
+	}
+
+	pid := status.State.PID
+	stopSignal := w.config.Tools[toolName].StopSignal
+	if stopSignal == "" {
+		stopSignal = defaultStopSignal
+	}
+	if err = signalService(pid, stopSignal); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return status, fmt.Errorf("failed to stop %s (PID %d): %w", toolName, pid, err)
+	}
+	// waitServiceExit checks the identity of the process, so a PID reused in the meantime is not killed.
+	if !waitServiceExit(ctx, status.State, killGracePeriod) {
+		if err = signalService(pid, killSignalName); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return status, fmt.Errorf("failed to kill %s (PID %d): %w", toolName, pid, err)
+		}
+		waitServiceExit(context.Background(), status.State, killGracePeriod)
+	}
+
+	if err = os.Remove(status.PIDFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return status, err
+	}
+	status.Running = false
+	return status, nil
+}
+
+// RestartService stops the service tool or alias if it is running and starts it again.
+// When userArgs is nil and name is the tool or the entry the service was last started with,
+// the service is started again with that entry and its arguments.
+func (w *Workspace) RestartService(ctx context.Context, name string, userArgs []string) (ServiceStatus, error) {
+	toolName, err := w.serviceToolName(name)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	lock, err := w.lockService(ctx, toolName)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	defer lock.Release()
+
+	status, err := w.stopService(ctx, toolName)
+	if err != nil && !errors.Is(err, ErrServiceNotRunning) {
+		return status, err
+	}
+	if userArgs == nil && status.State != nil && (name == status.Name || name == status.State.Entry) {
+		name, userArgs = status.State.Entry, status.State.UserArgs
+	}
+	inv, err := w.Resolve(name, userArgs)
+	if err != nil {
+		return status, err
+	}
+	return w.startService(name, userArgs, inv)
+}
+
+// lockService takes the exclusive tool lock of the service tool toolName, waiting for it until ctx is done.
+func (w *Workspace) lockService(ctx context.Context, toolName string) (*toolLock, error) {
+	return w.lockTool(ctx, Invocation{ToolName: toolName, Lock: LockExclusive}, InvokeOptions{})
+}
+
+// rotateServiceLog moves logFile to ToolOldLogFileName next to it when it is larger than ServiceLogMaxSize.
+func rotateServiceLog(logFile string) error {
+	info, err := os.Stat(logFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil || info.Size() <= ServiceLogMaxSize {
+		return err
+	}
+	return os.Rename(logFile, filepath.Join(filepath.Dir(logFile), ToolOldLogFileName))
+}
+
+// serviceToolName resolves name to the name of a service tool.
+// checkServiceRun decides whether Run may run the service tool of inv in the foreground.
+// Only the command line may, with a warning, and only while the service is not running in the background.
+func (w *Workspace) checkServiceRun(inv Invocation, opts InvokeOptions) error {
+	if opts.Origin != OriginCLI {
+		return fmt.Errorf("%w: %s", ErrServiceTool, inv.ToolName)
+	}
+	status, err := w.serviceStatus(inv.ToolName)
+	if err != nil {
+		return err
+	}
+	if status.Running {
+		return fmt.Errorf(
+			"%w: %s (PID %d); stop it before running it in the foreground",
+			ErrServiceRunning, inv.ToolName, status.State.PID,
+		)
+	}
+	fmt.Fprintf(
+		stderrOf(opts),
+		"sidetable: running service %s in the foreground; use \"sidetable start %s\" to run it in the background\n",
+		inv.ToolName, inv.ToolName,
+	)
+	return nil
+}
+
+func (w *Workspace) serviceToolName(name string) (string, error) {
+	if w == nil || w.config == nil {
+		return "", errors.New("workspace is not initialized")
+	}
+	resolved, err := w.config.ResolveEntry(name)
+	if err != nil {
+		return "", err
+	}
+	if !resolved.Tool.Service {
+		return "", fmt.Errorf("%w: %s", ErrNotService, resolved.ToolName)
+	}
+	return resolved.ToolName, nil
+}
+
+func (w *Workspace) serviceStatus(toolName string) (ServiceStatus, error) {
+	dir := toolDir(w.rootDir, w.config, toolName)
+	status := ServiceStatus{
+		Name:    toolName,
+		PIDFile: filepath.Join(dir, ToolPIDFileName),
+		LogFile: filepath.Join(dir, ToolLogFileName),
+	}
+
+	data, err := os.ReadFile(status.PIDFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	var state ServiceState
+	if jsonErr := json.Unmarshal(data, &state); jsonErr != nil || state.PID <= 0 {
+		// An unreadable PID file cannot point to a running service.
+		status.Stale = true
+		return status, nil
+	}
+	status.State = &state
+	status.Running = serviceRunning(&state)
+	status.Stale = !status.Running
+	return status, nil
+}
+
+// serviceRunning reports whether the process recorded in state still runs.
+// A live process with the recorded PID but a different start time is another process that reused the PID.
+func serviceRunning(state *ServiceState) bool {
+	if !serviceAlive(state.PID) {
+		return false
+	}
+	processStart, err := processStartTime(state.PID)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return true
+	}
+	return err == nil && processStart == state.ProcessStart
+}
+
+// waitServiceExit waits until the process recorded in state has exited, for at most timeout or until ctx is done,
+// and reports whether it exited.
+func waitServiceExit(ctx context.Context, state *ServiceState, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for serviceRunning(state) {
+		if sleepContext(ctx, serviceStopPollInterval) != nil {
+			return !serviceRunning(state)
+		}
+	}
+	return true
+}
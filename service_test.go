@@ -0,0 +1,336 @@
+//go:build !windows
+
+package sidetable_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable"
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func serviceTool(script string) config.Tool {
+	tool := shellTool(script)
+	tool.Service = true
+	return tool
+}
+
+// stopOnCleanup stops the service name when the test ends, in case the test did not.
+func stopOnCleanup(t *testing.T, ws *sidetable.Workspace, name string) {
+	t.Helper()
+	t.Cleanup(func() {
+		_, _ = ws.StopService(context.Background(), name)
+	})
+}
+
+func TestWorkspaceStartStopService(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`echo "serving $0"; exec sleep 30`),
+	}, map[string]config.Alias{
+		"web-dev": {Tool: "web", Args: config.Args{Append: []string{"dev"}}},
+	})
+	stopOnCleanup(t, ws, "web")
+
+	status, err := ws.StartService("web-dev", nil)
+	require.NoError(t, err)
+	require.True(t, status.Running)
+	require.Equal(t, "web", status.Name)
+	require.Equal(t, "web-dev", status.State.Entry)
+
+	toolDir, err := ws.ToolDir("web")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(toolDir, sidetable.ToolPIDFileName), status.PIDFile)
+	data, err := os.ReadFile(status.PIDFile)
+	require.NoError(t, err)
+	var state sidetable.ServiceState
+	require.NoError(t, json.Unmarshal(data, &state))
+	require.Equal(t, status.State.PID, state.PID)
+	require.NotEmpty(t, state.ProcessStart)
+
+	require.Eventually(t, func() bool {
+		logs, readErr := os.ReadFile(filepath.Join(toolDir, sidetable.ToolLogFileName))
+		return readErr == nil && strings.Contains(string(logs), "serving dev")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = ws.StartService("web", nil)
+	require.ErrorIs(t, err, sidetable.ErrServiceRunning)
+
+	services, err := ws.Services()
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	require.True(t, services[0].Running)
+
+	stopped, err := ws.StopService(context.Background(), "web")
+	require.NoError(t, err)
+	require.False(t, stopped.Running)
+	require.Equal(t, state.PID, stopped.State.PID)
+	require.NoFileExists(t, status.PIDFile)
+
+	_, err = ws.StopService(context.Background(), "web")
+	require.ErrorIs(t, err, sidetable.ErrServiceNotRunning)
+}
+
+func TestWorkspaceRunServiceInForeground(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web":   serviceTool(`echo "foreground"`),
+		"plain": shellTool("true"),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+	for _, entry := range catalog.Entries {
+		require.Equal(t, entry.Name == "web", entry.Service, entry.Name)
+	}
+
+	err = ws.Run(context.Background(), "web", nil, sidetable.InvokeOptions{Origin: sidetable.OriginMCP})
+	require.ErrorIs(t, err, sidetable.ErrServiceTool)
+	require.ErrorIs(t, ws.Run(context.Background(), "web", nil, sidetable.InvokeOptions{}), sidetable.ErrServiceTool)
+
+	var stdout, stderr bytes.Buffer
+	cli := sidetable.InvokeOptions{Origin: sidetable.OriginCLI, Stdout: &stdout, Stderr: &stderr}
+	require.NoError(t, ws.Run(context.Background(), "web", nil, cli))
+	require.Equal(t, "foreground\n", stdout.String())
+	require.Contains(t, stderr.String(), "running service web in the foreground")
+
+	ws = setupTestWorkspace(t, map[string]config.Tool{"web": serviceTool(`exec sleep 30`)}, nil)
+	stopOnCleanup(t, ws, "web")
+	_, err = ws.StartService("web", nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, ws.Run(context.Background(), "web", nil, cli), sidetable.ErrServiceRunning)
+}
+
+func TestWorkspaceRunningServiceDirIsLeftAlone(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{"web": serviceTool(`exec sleep 30`)}, nil)
+	stopOnCleanup(t, ws, "web")
+	status, err := ws.StartService("web", nil)
+	require.NoError(t, err)
+
+	candidates, err := ws.PruneCandidates(time.Hour, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, candidates)
+	require.ErrorIs(t, ws.RemoveAreaDir("web"), sidetable.ErrToolDirInUse)
+	_, err = ws.MoveToolDir("web", "server")
+	require.ErrorIs(t, err, sidetable.ErrToolDirInUse)
+	require.FileExists(t, status.PIDFile)
+
+	_, err = ws.StopService(context.Background(), "web")
+	require.NoError(t, err)
+	moved, err := ws.MoveToolDir("web", "server")
+	require.NoError(t, err)
+	require.True(t, moved)
+}
+
+func TestWorkspaceRestartServiceKeepsArgs(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`exec sleep 30`),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	first, err := ws.StartService("web", []string{"--port", "8080"})
+	require.NoError(t, err)
+
+	restarted, err := ws.RestartService(context.Background(), "web", nil)
+	require.NoError(t, err)
+	require.True(t, restarted.Running)
+	require.NotEqual(t, first.State.PID, restarted.State.PID)
+	require.Equal(t, []string{"--port", "8080"}, restarted.State.UserArgs)
+}
+
+func TestWorkspaceStopServiceUsesStopSignal(t *testing.T) {
+	tool := serviceTool(`trap 'echo "got INT"; exit 0' INT; while :; do sleep 0.05; done`)
+	tool.StopSignal = "SIGINT"
+	ws := setupTestWorkspace(t, map[string]config.Tool{"worker": tool}, nil)
+	stopOnCleanup(t, ws, "worker")
+
+	status, err := ws.StartService("worker", nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = ws.StopService(context.Background(), "worker")
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 5*time.Second, "service should exit on its stop signal, not be killed")
+
+	logs, err := os.ReadFile(status.LogFile)
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "got INT")
+}
+
+func TestWorkspaceServiceStalePID(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`exec sleep 30`),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	status, err := ws.ServiceStatus("web")
+	require.NoError(t, err)
+	require.False(t, status.Running)
+	require.False(t, status.Stale)
+	require.Nil(t, status.State)
+
+	// A process that has exited and been reaped leaves a PID nothing runs under.
+	exited := exec.Command("true")
+	require.NoError(t, exited.Run())
+	pid := exited.Process.Pid
+	require.NoError(t, os.MkdirAll(filepath.Dir(status.PIDFile), 0o755))
+	require.NoError(t, os.WriteFile(status.PIDFile, []byte(`{"pid": `+strconv.Itoa(pid)+`}`), 0o600))
+
+	status, err = ws.ServiceStatus("web")
+	require.NoError(t, err)
+	require.False(t, status.Running)
+	require.True(t, status.Stale)
+
+	started, err := ws.StartService("web", nil)
+	require.NoError(t, err)
+	require.True(t, started.Running)
+	require.NotEqual(t, pid, started.State.PID)
+}
+
+func TestWorkspaceServiceIgnoresReusedPID(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`exec sleep 30`),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	// An unrelated process that now has the PID recorded for the service.
+	other := exec.Command("sleep", "30")
+	require.NoError(t, other.Start())
+	exited := make(chan struct{})
+	go func() {
+		_ = other.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = other.Process.Kill()
+		<-exited
+	})
+
+	status, err := ws.ServiceStatus("web")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(status.PIDFile), 0o755))
+	for _, state := range []string{
+		`{"pid": ` + strconv.Itoa(other.Process.Pid) + `, "process_start": "1"}`,
+		`{"pid": ` + strconv.Itoa(other.Process.Pid) + `}`,
+	} {
+		require.NoError(t, os.WriteFile(status.PIDFile, []byte(state), 0o600))
+
+		status, err = ws.ServiceStatus("web")
+		require.NoError(t, err)
+		require.False(t, status.Running, state)
+		require.True(t, status.Stale, state)
+
+		_, err = ws.StopService(context.Background(), "web")
+		require.ErrorIs(t, err, sidetable.ErrServiceNotRunning)
+		require.Never(t, func() bool {
+			select {
+			case <-exited:
+				return true
+			default:
+				return false
+			}
+		}, 200*time.Millisecond, 10*time.Millisecond, "the unrelated process must not be signalled")
+	}
+
+	require.NoError(t, os.WriteFile(status.PIDFile, []byte(`{"pid": `+strconv.Itoa(other.Process.Pid)+`}`), 0o600))
+	started, err := ws.StartService("web", nil)
+	require.NoError(t, err)
+	require.NotEqual(t, other.Process.Pid, started.State.PID)
+}
+
+func TestWorkspaceStartServiceConcurrently(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`exec sleep 30`),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	const starts = 4
+	errs := make(chan error, starts)
+	var wg sync.WaitGroup
+	for range starts {
+		wg.Go(func() {
+			_, err := ws.StartService("web", nil)
+			errs <- err
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	started := 0
+	for err := range errs {
+		if err == nil {
+			started++
+			continue
+		}
+		require.ErrorIs(t, err, sidetable.ErrServiceRunning)
+	}
+	require.Equal(t, 1, started, "only one start may spawn the service")
+}
+
+func TestWorkspaceStartServiceRotatesLargeLog(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"web": serviceTool(`echo "fresh"; exec sleep 30`),
+	}, nil)
+	stopOnCleanup(t, ws, "web")
+
+	status, err := ws.ServiceStatus("web")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(status.LogFile), 0o755))
+	old := bytes.Repeat([]byte("x"), sidetable.ServiceLogMaxSize+1)
+	require.NoError(t, os.WriteFile(status.LogFile, old, 0o600))
+
+	_, err = ws.StartService("web", nil)
+	require.NoError(t, err)
+
+	rotated, err := os.Stat(filepath.Join(filepath.Dir(status.LogFile), sidetable.ToolOldLogFileName))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(old)), rotated.Size())
+	require.Eventually(t, func() bool {
+		logs, readErr := os.ReadFile(status.LogFile)
+		return readErr == nil && strings.Contains(string(logs), "fresh") && len(logs) < len(old)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWorkspaceStartServiceExitsImmediately(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"broken": serviceTool(`echo "boom" >&2; exit 3`),
+	}, nil)
+
+	_, err := ws.StartService("broken", nil)
+	require.ErrorContains(t, err, "exited right after starting")
+
+	status, err := ws.ServiceStatus("broken")
+	require.NoError(t, err)
+	require.NoFileExists(t, status.PIDFile)
+	logs, err := os.ReadFile(status.LogFile)
+	require.NoError(t, err)
+	require.Contains(t, string(logs), "boom")
+}
+
+func TestWorkspaceServiceRequiresServiceTool(t *testing.T) {
+	ws := setupTestWorkspace(t, map[string]config.Tool{
+		"plain": shellTool(`:`),
+	}, nil)
+
+	_, err := ws.StartService("plain", nil)
+	require.ErrorIs(t, err, sidetable.ErrNotService)
+	_, err = ws.StopService(context.Background(), "plain")
+	require.ErrorIs(t, err, sidetable.ErrNotService)
+	_, err = ws.ServiceStatus("plain")
+	require.ErrorIs(t, err, sidetable.ErrNotService)
+
+	services, err := ws.Services()
+	require.NoError(t, err)
+	require.Empty(t, services)
+}
@@ -0,0 +1,150 @@
+package sidetable
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirStatus describes a directory in the tool area.
+type DirStatus struct {
+	// Name is the tool name for configured tools and the directory name for orphans.
+	Name string
+	Path string
+	// Configured is true when a tool with this name exists in the config.
+	Configured bool
+	Exists     bool
+	// Backup is true for copies of a tool directory that Import kept with ConflictRename.
+	Backup bool
+	// Symlink is true when the directory is a symbolic link. Symlinks are never followed.
+	Symlink bool
+	// Size is the total size of regular files in bytes.
+	Size  int64
+	Files int
+	// ModTime is the latest modification time of the directory or anything in it.
+	ModTime time.Time
+}
+
+// AreaStatus reports on the tool area and the directories in it.
+type AreaStatus struct {
+	Dir    string
+	Exists bool
+	// Tools has an entry for every configured tool, sorted by name.
+	Tools []DirStatus
+	// Orphans lists directories that do not belong to any configured tool, sorted by name.
+	Orphans []DirStatus
+	// Backups lists directories named "<tool>.bak.<timestamp>" that Import kept for configured tools, sorted by name.
+	Backups []DirStatus
+}
+
+// AreaStatus walks the tool area and reports disk usage per tool directory.
+func (w *Workspace) AreaStatus() (*AreaStatus, error) {
+	if w == nil || w.config == nil {
+		return nil, errors.New("workspace is not initialized")
+	}
+
+	status := &AreaStatus{Dir: w.AreaDir()}
+	// owned holds the top-level entries of the area that contain configured tool directories.
+	owned := make(map[string]bool, len(w.config.Tools))
+	for _, name := range w.config.ToolNames() {
+		path := toolDir(w.rootDir, w.config, name)
+		dir, err := statDir(path)
+		if err != nil {
+			return nil, err
+		}
+		dir.Name = name
+		dir.Configured = true
+		status.Tools = append(status.Tools, dir)
+
+		if rel, relErr := filepath.Rel(status.Dir, path); relErr == nil {
+			owned[strings.Split(filepath.ToSlash(rel), "/")[0]] = true
+		}
+	}
+
+	entries, err := os.ReadDir(status.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status.Exists = true
+
+	for _, entry := range entries {
+		// Staging directories belong to an import that is running or was interrupted; Import removes them itself.
+		if owned[entry.Name()] || strings.HasPrefix(entry.Name(), archiveStagingPrefix) {
+			continue
+		}
+		if !entry.IsDir() && entry.Type()&fs.ModeSymlink == 0 {
+			continue
+		}
+		dir, statErr := statDir(filepath.Join(status.Dir, entry.Name()))
+		if statErr != nil {
+			return nil, statErr
+		}
+		if tool, ok := backupToolName(entry.Name()); ok {
+			if _, configured := w.config.Tools[tool]; configured {
+				dir.Backup = true
+				status.Backups = append(status.Backups, dir)
+				continue
+			}
+		}
+		status.Orphans = append(status.Orphans, dir)
+	}
+	sort.Slice(status.Orphans, func(i, j int) bool {
+		return status.Orphans[i].Name < status.Orphans[j].Name
+	})
+	sort.Slice(status.Backups, func(i, j int) bool {
+		return status.Backups[i].Name < status.Backups[j].Name
+	})
+
+	return status, nil
+}
+
+// statDir summarizes the directory at path without following symlinks.
+func statDir(path string) (DirStatus, error) {
+	status := DirStatus{Name: filepath.Base(path), Path: path}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+	status.Exists = true
+	status.ModTime = info.ModTime()
+	if info.Mode()&fs.ModeSymlink != 0 {
+		status.Symlink = true
+		return status, nil
+	}
+	if !info.IsDir() {
+		status.Size = info.Size()
+		status.Files = 1
+		return status, nil
+	}
+
+	// WalkDir does not follow symlinks, so the walk stays inside path.
+	err = filepath.WalkDir(path, func(_ string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		entryInfo, infoErr := d.Info()
+		if infoErr != nil {
+			return infoErr
+		}
+		if entryInfo.ModTime().After(status.ModTime) {
+			status.ModTime = entryInfo.ModTime()
+		}
+		if entryInfo.Mode().IsRegular() {
+			status.Size += entryInfo.Size()
+			status.Files++
+		}
+		return nil
+	})
+	return status, err
+}
@@ -0,0 +1,73 @@
+package sidetable_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sushichan044/sidetable/internal/config"
+)
+
+func TestWorkspaceAreaStatus(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"ghq":  {Run: "ghq"},
+			"note": {Run: "vim"},
+		},
+		nil,
+	)
+
+	status, err := ws.AreaStatus()
+	require.NoError(t, err)
+	require.False(t, status.Exists)
+	require.Len(t, status.Tools, 2)
+	require.False(t, status.Tools[0].Exists)
+
+	area := ws.AreaDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "ghq", "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(area, "ghq", "a"), []byte("12345"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(area, "ghq", "sub", "b"), []byte("123"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "old"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(area, "stray-file"), []byte("x"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(area, ".import-123"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "ghq.bak.20260101-120000"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(area, "gone.bak.20260101-120000"), 0o755))
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "big"), make([]byte, 1024), 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(area, "link")))
+
+	past := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(area, "ghq", "sub", "b"), past, past))
+
+	status, err = ws.AreaStatus()
+	require.NoError(t, err)
+	require.True(t, status.Exists)
+
+	ghq := status.Tools[0]
+	require.Equal(t, "ghq", ghq.Name)
+	require.True(t, ghq.Exists)
+	require.True(t, ghq.Configured)
+	require.Equal(t, int64(8), ghq.Size)
+	require.Equal(t, 2, ghq.Files)
+	require.WithinDuration(t, time.Now(), ghq.ModTime, time.Minute)
+
+	require.False(t, status.Tools[1].Exists)
+
+	// Backups of tools that are no longer configured are orphans; staging directories of imports are left alone.
+	require.Len(t, status.Orphans, 3)
+	require.Equal(t, "gone.bak.20260101-120000", status.Orphans[0].Name)
+	require.Equal(t, "link", status.Orphans[1].Name)
+	require.True(t, status.Orphans[1].Symlink)
+	require.Zero(t, status.Orphans[1].Size)
+	require.Equal(t, "old", status.Orphans[2].Name)
+	require.False(t, status.Orphans[2].Configured)
+
+	require.Len(t, status.Backups, 1)
+	require.Equal(t, "ghq.bak.20260101-120000", status.Backups[0].Name)
+	require.True(t, status.Backups[0].Backup)
+}
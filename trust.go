@@ -0,0 +1,70 @@
+package sidetable
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/trust"
+)
+
+// ErrProjectConfigUntrusted is returned when only an untrusted project-level config is available.
+var ErrProjectConfigUntrusted = errors.New("project config is not trusted")
+
+const (
+	trustHint   = `run "sidetable workspaces trust" in the workspace to use it`
+	retrustHint = `run "sidetable workspaces trust" again after reviewing it to use it`
+)
+
+// workspaceConfig is the config resolved for a workspace.
+type workspaceConfig struct {
+	path string
+	// content is the config source when it was read while checking trust, so that the checked contents are loaded.
+	content []byte
+	// warning reports a project-level config that was skipped.
+	warning string
+}
+
+// FindConfigPath returns the config used for the workspace rooted at root:
+// the project-level config when it exists and is trusted, otherwise the global config.
+func FindConfigPath(root string) (string, error) {
+	resolved, err := resolveWorkspaceConfig(root)
+	return resolved.path, err
+}
+
+// resolveWorkspaceConfig finds the config used for the workspace rooted at root.
+//
+// A project-level config comes with the repository, so it is only loaded once the user has trusted
+// its current contents.
+func resolveWorkspaceConfig(root string) (workspaceConfig, error) {
+	project := config.ProjectConfigPath(root)
+	content, err := os.ReadFile(project)
+	if errors.Is(err, fs.ErrNotExist) {
+		path, findErr := config.FindConfigPath()
+		return workspaceConfig{path: path}, findErr
+	} else if err != nil {
+		return workspaceConfig{}, err
+	}
+
+	status, err := trust.Check(project, content)
+	if err != nil {
+		return workspaceConfig{}, err
+	}
+	if status == trust.Trusted {
+		return workspaceConfig{path: project, content: content}, nil
+	}
+
+	subject, hint := "untrusted project config "+project, trustHint
+	if status == trust.Changed {
+		subject, hint = "project config "+project+", which changed since it was trusted", retrustHint
+	}
+
+	path, err := config.FindConfigPath()
+	if errors.Is(err, config.ErrConfigMissing) {
+		return workspaceConfig{}, fmt.Errorf("%w: cannot use %s; %s", ErrProjectConfigUntrusted, subject, hint)
+	}
+	warning := fmt.Sprintf("ignoring %s; %s", subject, hint)
+	return workspaceConfig{path: path, warning: warning}, err
+}
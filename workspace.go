@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/sushichan044/sidetable/internal/config"
 )
@@ -20,7 +21,8 @@ type Workspace struct {
 type Option func(*workspaceOptions)
 
 type workspaceOptions struct {
-	configPath string
+	configPath  string
+	loadOptions []config.LoadOption
 }
 
 // WithConfigPath overrides config path resolution.
@@ -30,7 +32,17 @@ func WithConfigPath(path string) Option {
 	}
 }
 
+// WithLenientConfig ignores unknown config fields instead of reporting them in Warnings.
+func WithLenientConfig() Option {
+	return func(o *workspaceOptions) {
+		o.loadOptions = append(o.loadOptions, config.WithLenient())
+	}
+}
+
 // Open loads config and prepares workspace context.
+//
+// Unless WithConfigPath is given, the project-level config in root is used when the user has trusted it,
+// otherwise the global config. A skipped project-level config is reported in Warnings.
 func Open(root string, opts ...Option) (*Workspace, error) {
 	if root == "" {
 		return nil, errors.New("root must not be empty")
@@ -53,18 +65,26 @@ func Open(root string, opts ...Option) (*Workspace, error) {
 		opt(&openOpts)
 	}
 
-	path := openOpts.configPath
-	if path == "" {
-		path, err = config.FindConfigPath()
+	resolved := workspaceConfig{path: openOpts.configPath}
+	if resolved.path == "" {
+		resolved, err = resolveWorkspaceConfig(root)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	cfg, err := config.Load(path)
+	var cfg *config.Config
+	if resolved.content != nil {
+		cfg, err = config.Parse(resolved.content, resolved.path, openOpts.loadOptions...)
+	} else {
+		cfg, err = config.Load(resolved.path, openOpts.loadOptions...)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if resolved.warning != "" {
+		cfg.Warnings = append(cfg.Warnings, resolved.warning)
+	}
 
 	return &Workspace{config: cfg, rootDir: root}, nil
 }
@@ -77,16 +97,70 @@ func (w *Workspace) Root() string {
 	return w.rootDir
 }
 
-// Run resolves then executes a tool or alias.
-func (w *Workspace) Run(ctx context.Context, name string, userArgs []string, opts InvokeOptions) error {
+// Warnings returns non-fatal problems found while loading the config.
+func (w *Workspace) Warnings() []string {
 	if w == nil || w.config == nil {
-		return errors.New("workspace is not initialized")
+		return nil
+	}
+	return w.config.Warnings
+}
+
+// Resolve resolves a tool or alias into the invocation Run would execute, without executing it.
+//
+// When the program cannot be found, the resolved invocation is returned together with a ProgramNotFoundError.
+func (w *Workspace) Resolve(name string, userArgs []string) (Invocation, error) {
+	if w == nil || w.config == nil {
+		return Invocation{}, errors.New("workspace is not initialized")
 	}
 
 	inv, err := resolveInvocation(w.config, name, userArgs, w.rootDir, os.Environ())
+	if err != nil {
+		return Invocation{}, err
+	}
+	if err = lookupProgram(&inv); err != nil {
+		return inv, err
+	}
+	return inv, nil
+}
+
+// Run resolves then executes a tool or alias.
+//
+// Depending on opts.Exec, the current process may be replaced with the tool, in which case Run does not return.
+func (w *Workspace) Run(ctx context.Context, name string, userArgs []string, opts InvokeOptions) error {
+	inv, err := w.Resolve(name, userArgs)
+	if err != nil {
+		if _, notFound := AsProgramNotFoundError(err); notFound {
+			w.recordHistory(name, userArgs, inv, opts, time.Now(), err)
+		}
+		return err
+	}
+	if inv.Service {
+		if err = w.checkServiceRun(inv, opts); err != nil {
+			return err
+		}
+	}
+
+	if w.config.GitExclude {
+		if _, err = w.EnsureGitExclude(); err != nil {
+			return fmt.Errorf("failed to exclude the tool area from git: %w", err)
+		}
+	}
+	lock, err := w.lockTool(ctx, inv, opts)
 	if err != nil {
 		return err
 	}
+	defer lock.Release()
+
+	start := time.Now()
+	w.recordUse(start)
+
+	if canReplaceProcess(inv, opts) {
+		if err = lock.KeepOnExec(); err != nil {
+			return err
+		}
+		w.recordExec(name, userArgs, inv, opts, start)
+		return replaceProcess(inv)
+	}
 
-	return w.execute(ctx, inv, opts)
+	return w.executeWithRetry(ctx, name, userArgs, inv, opts)
 }
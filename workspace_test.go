@@ -3,14 +3,17 @@ package sidetable_test
 import (
 	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sushichan044/sidetable"
 	"github.com/sushichan044/sidetable/internal/config"
+	"github.com/sushichan044/sidetable/internal/trust"
 )
 
 func setupTestWorkspace(
@@ -20,15 +23,20 @@ func setupTestWorkspace(
 ) *sidetable.Workspace {
 	t.Helper()
 
+	return setupTestWorkspaceWithConfig(t, &config.Config{Tools: tools, Aliases: aliases})
+}
+
+// setupTestWorkspaceWithConfig opens a workspace for cfg, filling in the version and directory.
+func setupTestWorkspaceWithConfig(t *testing.T, cfg *config.Config) *sidetable.Workspace {
+	t.Helper()
+
 	// Create a temporary directory for the test workspace.
 	projectDir := t.TempDir()
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
 
 	// Write the config file.
-	cfg := &config.Config{
-		Directory: ".sidetable",
-		Tools:     tools,
-		Aliases:   aliases,
-	}
+	cfg.Version = config.CurrentVersion
+	cfg.Directory = ".sidetable"
 	configContent, err := yaml.Marshal(cfg)
 	require.NoError(t, err)
 	configPath := filepath.Join(projectDir, ".sidetable.yml")
@@ -84,3 +92,291 @@ func TestWorkspaceRun(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestWorkspaceRunTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skipf("skipping test; sleep not found: %v", err)
+	}
+
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"slow": {Run: "sleep", Args: config.Args{Prepend: []string{"10"}}, Timeout: "100ms"},
+		},
+		map[string]config.Alias{
+			"slower": {Tool: "slow", Timeout: "1h"},
+		},
+	)
+
+	start := time.Now()
+	err := ws.Run(context.Background(), "slow", nil, sidetable.InvokeOptions{})
+	timeoutErr, ok := sidetable.AsTimeoutError(err)
+	require.True(t, ok, "expected timeout error, got %v", err)
+	require.Equal(t, 100*time.Millisecond, timeoutErr.Timeout)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	err = ws.Run(context.Background(), "slower", nil, sidetable.InvokeOptions{Timeout: 50 * time.Millisecond})
+	timeoutErr, ok = sidetable.AsTimeoutError(err)
+	require.True(t, ok, "expected timeout error, got %v", err)
+	require.Equal(t, 50*time.Millisecond, timeoutErr.Timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ws.Run(ctx, "slower", nil, sidetable.InvokeOptions{})
+	require.Error(t, err)
+	_, ok = sidetable.AsTimeoutError(err)
+	require.False(t, ok, "cancellation by the caller is not a timeout")
+}
+
+func TestWorkspaceCatalogMarksUnavailableTools(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"everywhere": {Run: "echo"},
+			"plan9-only": {
+				Platforms: map[string]config.PlatformOverride{"plan9": {Run: "echo"}},
+			},
+		},
+		map[string]config.Alias{
+			"p9": {Tool: "plan9-only"},
+		},
+	)
+
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+
+	available := make(map[string]bool, len(catalog.Entries))
+	for _, entry := range catalog.Entries {
+		available[entry.Name] = entry.Available
+	}
+	require.Equal(t, map[string]bool{"everywhere": true, "plan9-only": false, "p9": false}, available)
+
+	err = ws.Run(context.Background(), "p9", nil, sidetable.InvokeOptions{})
+	require.ErrorIs(t, err, config.ErrToolUnavailable)
+}
+
+func TestWorkspaceCatalogIncludesRunAndEnvKeys(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"ghq": {Run: "ghq", Env: map[string]string{"GHQ_ROOT": "{{.ToolDir}}", "A": "1"}},
+		},
+		map[string]config.Alias{
+			"gg": {Tool: "ghq"},
+		},
+	)
+
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+	require.Len(t, catalog.Entries, 2)
+	for _, entry := range catalog.Entries {
+		require.Equal(t, "ghq", entry.Run, entry.Name)
+		require.Equal(t, []string{"A", "GHQ_ROOT"}, entry.EnvKeys, entry.Name)
+	}
+}
+
+func TestCatalogFilter(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"ghq":  {Run: "ghq", Description: "Manage repositories", Tags: []string{"git"}},
+			"note": {Run: "vim", Instructions: "Edit the PROJECT notes", Tags: []string{"docs"}},
+			"db":   {Run: "psql"},
+		},
+		map[string]config.Alias{
+			"gg": {Tool: "ghq", Tags: []string{"shortcut"}},
+		},
+	)
+
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+
+	names := func(entries []sidetable.Entry) []string {
+		result := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, entry.Name)
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"db", "ghq", "note", "gg"}, names(catalog.Filter(sidetable.EntryFilter{})))
+	require.Equal(t, []string{"ghq", "gg"}, names(catalog.Filter(sidetable.EntryFilter{Tags: []string{"git"}})))
+	require.Equal(t, []string{"ghq", "note", "gg"}, names(catalog.Filter(sidetable.EntryFilter{Tags: []string{"git", "docs"}})))
+	require.Equal(t, []string{"ghq"}, names(catalog.Filter(sidetable.EntryFilter{
+		Kind: sidetable.EntryKindTool,
+		Tags: []string{"git"},
+	})))
+	require.Equal(t, []string{"note"}, names(catalog.Filter(sidetable.EntryFilter{Search: "project"})))
+	require.Equal(t, []string{"ghq"}, names(catalog.Filter(sidetable.EntryFilter{Search: "REPO"})))
+
+	for _, entry := range catalog.Entries {
+		if entry.Name == "gg" {
+			require.Equal(t, []string{"git", "shortcut"}, entry.Tags)
+		}
+	}
+}
+
+func TestWorkspaceCatalogReportsGroups(t *testing.T) {
+	ws := setupTestWorkspaceWithConfig(t, &config.Config{
+		Tools: map[string]config.Tool{
+			"db.migrate":     {Run: "echo"},
+			"db.schema.dump": {Platforms: map[string]config.PlatformOverride{"plan9": {Run: "echo"}}},
+			"go1.22":         {Run: "echo"},
+		},
+		Aliases: map[string]config.Alias{
+			"db.m": {Tool: "db.migrate"},
+		},
+		Groups: map[string]config.Group{
+			"db.schema": {},
+		},
+	})
+
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, []sidetable.Group{
+		{Name: "db", Words: []string{"db"}, Available: true},
+		{Name: "db.schema", Words: []string{"db", "schema"}, Parent: "db", Available: false},
+	}, catalog.Groups)
+
+	groups := make(map[string]string, len(catalog.Entries))
+	for _, entry := range catalog.Entries {
+		groups[entry.Name] = entry.Group
+	}
+	require.Equal(t, map[string]string{
+		"db.migrate":     "db",
+		"db.schema.dump": "db.schema",
+		"db.m":           "db",
+		"go1.22":         "",
+	}, groups)
+
+	inv, err := ws.Resolve("db migrate", []string{"x"})
+	require.NoError(t, err)
+	require.Equal(t, "db.migrate", inv.ToolName)
+}
+
+func TestWorkspaceResolve(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{
+			"hello":   {Run: "echo", Env: map[string]string{"HELLO_DIR": "{{.ToolDir}}"}},
+			"missing": {Run: "definitely-not-a-real-program-12345"},
+		},
+		nil,
+	)
+
+	inv, err := ws.Resolve("hello", []string{"world"})
+	require.NoError(t, err)
+	require.Equal(t, "echo", inv.Program)
+	require.True(t, filepath.IsAbs(inv.Path))
+	require.Equal(t, []string{"world"}, inv.Args)
+	require.Contains(t, inv.Env, "HELLO_DIR="+filepath.Join(ws.Root(), ".sidetable", "hello"))
+
+	inv, err = ws.Resolve("missing", nil)
+	_, notFound := sidetable.AsProgramNotFoundError(err)
+	require.True(t, notFound)
+	require.Equal(t, "definitely-not-a-real-program-12345", inv.Program)
+	require.Empty(t, inv.Path)
+}
+
+func TestEnvDiff(t *testing.T) {
+	changes := sidetable.EnvDiff(
+		[]string{"KEEP=1", "MOD=old", "GONE=x"},
+		[]string{"KEEP=1", "MOD=new", "ADD=y"},
+	)
+	require.Equal(t, []sidetable.EnvChange{
+		{Key: "ADD", Kind: sidetable.EnvChangeAdded, Value: "y"},
+		{Key: "GONE", Kind: sidetable.EnvChangeRemoved, Previous: "x"},
+		{Key: "MOD", Kind: sidetable.EnvChangeModified, Value: "new", Previous: "old"},
+	}, changes)
+}
+
+func TestOpenPrefersTrustedProjectConfig(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	globalDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(globalDir, "config.yml"),
+		[]byte("version: 1\ndirectory: .global\ntools:\n  global:\n    run: echo\n"),
+		0o600,
+	))
+	t.Setenv("SIDETABLE_CONFIG_DIR", globalDir)
+
+	projectDir := t.TempDir()
+	ws, err := sidetable.Open(projectDir)
+	require.NoError(t, err)
+	catalog, err := ws.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, "global", catalog.Entries[0].Name)
+
+	require.NoError(t, os.WriteFile(
+		config.ProjectConfigPath(projectDir),
+		[]byte("version: 1\ndirectory: .local\ntools:\n  local:\n    run: echo\n"),
+		0o600,
+	))
+	ws, err = sidetable.Open(projectDir)
+	require.NoError(t, err)
+	catalog, err = ws.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, "global", catalog.Entries[0].Name, "an untrusted project config must not be loaded")
+	require.Len(t, ws.Warnings(), 1)
+	require.Contains(t, ws.Warnings()[0], "ignoring untrusted project config")
+
+	_, err = trust.Add(config.ProjectConfigPath(projectDir), time.Now())
+	require.NoError(t, err)
+	ws, err = sidetable.Open(projectDir)
+	require.NoError(t, err)
+	catalog, err = ws.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, "local", catalog.Entries[0].Name)
+	require.Empty(t, ws.Warnings())
+
+	require.NoError(t, os.WriteFile(
+		config.ProjectConfigPath(projectDir),
+		[]byte("version: 1\ndirectory: .local\ntools:\n  changed:\n    run: echo\n"),
+		0o600,
+	))
+	ws, err = sidetable.Open(projectDir)
+	require.NoError(t, err)
+	catalog, err = ws.Catalog()
+	require.NoError(t, err)
+	require.Equal(t, "global", catalog.Entries[0].Name, "a project config changed since it was trusted must not be loaded")
+	require.Len(t, ws.Warnings(), 1)
+	require.Contains(t, ws.Warnings()[0], "changed since it was trusted")
+	require.Contains(t, ws.Warnings()[0], `run "sidetable workspaces trust" again`)
+}
+
+func TestOpenUntrustedProjectConfigWithoutGlobalConfig(t *testing.T) {
+	t.Setenv("SIDETABLE_STATE_DIR", t.TempDir())
+	t.Setenv("SIDETABLE_CONFIG_DIR", t.TempDir())
+
+	projectDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		config.ProjectConfigPath(projectDir),
+		[]byte("version: 1\ndirectory: .local\ntools:\n  local:\n    run: echo\n"),
+		0o600,
+	))
+	_, err := sidetable.Open(projectDir)
+	require.ErrorIs(t, err, sidetable.ErrProjectConfigUntrusted)
+	require.ErrorContains(t, err, "sidetable workspaces trust")
+}
+
+func TestWorkspaceToolDir(t *testing.T) {
+	ws := setupTestWorkspace(
+		t,
+		map[string]config.Tool{"ghq": {Run: "ghq"}},
+		map[string]config.Alias{"gg": {Tool: "ghq"}},
+	)
+
+	require.Equal(t, filepath.Join(ws.Root(), ".sidetable"), ws.AreaDir())
+
+	dir, err := ws.ToolDir("gg")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(ws.Root(), ".sidetable", "ghq"), dir)
+	require.NoDirExists(t, dir)
+
+	dir, err = ws.EnsureToolDir("ghq")
+	require.NoError(t, err)
+	require.DirExists(t, dir)
+
+	_, err = ws.ToolDir("missing")
+	require.ErrorIs(t, err, config.ErrEntryUnknown)
+}